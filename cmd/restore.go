@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ga1az/pathdigest/internal/digest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreDryRun    bool
+	restoreOverwrite bool
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <digest> [target-dir]",
+	Short: "Rebuild a file tree from a text or JSON digest",
+	Long: `restore parses a pathdigest text or JSON digest (use "-" to read from stdin)
+and writes the files it contains below target-dir (default: current directory).
+
+Entries without usable content, such as non-text or truncated files, are
+reported and skipped. Paths that would end up outside target-dir are refused.
+
+Text digests are not byte-exact: every restored file ends with a newline and
+empty files are missing. Use a JSON digest for an exact restore.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading digest: %v\n", err)
+			os.Exit(1)
+		}
+
+		targetDir := "."
+		if len(args) > 1 {
+			targetDir = args[1]
+		}
+
+		entries, err := digest.ParseDigest(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing digest: %v\n", err)
+			os.Exit(1)
+		}
+
+		report, err := digest.RestoreEntries(entries, targetDir, digest.RestoreOptions{
+			DryRun:    restoreDryRun,
+			Overwrite: restoreOverwrite,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring digest: %v\n", err)
+			os.Exit(1)
+		}
+
+		verb := "Wrote"
+		if restoreDryRun {
+			verb = "Would write"
+		}
+		for _, e := range report.Written {
+			fmt.Printf("%s %s (%d bytes)\n", verb, e.Path, len(e.Content))
+		}
+		for _, e := range report.Existing {
+			fmt.Fprintf(os.Stderr, "Skipped existing file %s (use --overwrite to replace it)\n", e.Path)
+		}
+		for _, e := range report.Placeholders {
+			fmt.Fprintf(os.Stderr, "Skipped placeholder %s (%s)\n", e.Path, e.Placeholder)
+		}
+
+		fmt.Fprintf(os.Stderr, "\n%s %d files, skipped %d existing and %d placeholders in %s\n",
+			verb, len(report.Written), len(report.Existing), len(report.Placeholders), targetDir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().BoolVarP(&restoreDryRun, "dry-run", "n", false, "List the files that would be written without writing them")
+	restoreCmd.Flags().BoolVar(&restoreOverwrite, "overwrite", false, "Replace files that already exist in the target directory")
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ga1az/pathdigest/internal/digest"
 	"github.com/spf13/cobra"
@@ -28,6 +32,27 @@ var (
 	includePatterns []string
 	branch          string
 	outputFormat    string
+	truncateLarge   string
+	order           string
+	priority        []string
+	modelName       string
+	modelsFile      string
+	templateFile    string
+	lineNumbers     bool
+	stripComments   bool
+	keepDocComments bool
+	outlineLangs    []string
+	outlineKeep     []string
+	compact         bool
+	compactTabs     int
+	secretsMode     string
+	secretsAllow    string
+	redactPII       bool
+	piiConfig       string
+	dedupe          bool
+	listDeps        bool
+	stripLicenses   bool
+	licenseTemplate string
 )
 
 var rootCmd = &cobra.Command{
@@ -73,69 +98,175 @@ You can specify a local path or a repository URL as the source.`,
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate format flag early
-		if outputFormat != "text" && outputFormat != "json" {
-			fmt.Fprintf(os.Stderr, "Error: unsupported format '%s'. Use 'text' or 'json'.\n", outputFormat)
-			os.Exit(1)
+		var formatter digest.Formatter
+		if templateFile != "" {
+			f, errTmpl := digest.NewTemplateFormatter(templateFile)
+			if errTmpl != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", errTmpl)
+				os.Exit(1)
+			}
+			formatter = f
+		} else {
+			f, ok := digest.LookupFormatter(outputFormat)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: unsupported format '%s'. Use one of: %s.\n", outputFormat, strings.Join(digest.FormatterNames(), ", "))
+				os.Exit(1)
+			}
+			formatter = f
+		}
+		if !cmd.Flags().Changed("output") {
+			outputFile = digest.DefaultOutputFile(formatter.Name())
 		}
 
-		source := args[0]
+		if !digest.IsValidOrder(order) {
+			fmt.Fprintf(os.Stderr, "Error: unsupported order '%s'. Use one of: %s.\n", order, strings.Join(digest.OrderStrategies, ", "))
+			os.Exit(1)
+		}
 
-		opts := digest.IngestionOptions{
-			Source:          source,
-			OutputFile:      outputFile,
-			MaxFileSize:     maxFileSize,
-			ExcludePatterns: excludePatterns,
-			IncludePatterns: includePatterns,
-			Branch:          branch,
+		for _, lang := range outlineLangs {
+			if !digest.IsValidOutlineLanguage(lang) {
+				fmt.Fprintf(os.Stderr, "Error: unsupported outline language '%s'. Use one of: %s.\n", lang, strings.Join(digest.OutlineLanguages(), ", "))
+				os.Exit(1)
+			}
 		}
 
-		fmt.Fprintf(os.Stderr, "Processing source: %s\n", opts.Source)
-		if opts.Branch != "" {
-			fmt.Fprintf(os.Stderr, "Targeting branch: %s\n", opts.Branch)
+		if !digest.IsValidSecretsMode(secretsMode) {
+			fmt.Fprintf(os.Stderr, "Error: unsupported secrets mode '%s'. Use one of: %s.\n", secretsMode, strings.Join(digest.SecretsModes, ", "))
+			os.Exit(1)
 		}
 
-		ingestResult, err := digest.ProcessSource(opts)
+		truncateSpec, err := digest.ParseTruncateSpec(truncateLarge)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error processing source: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Route to the correct formatter — exactly one call
-		if outputFormat == "json" {
-			jsonBytes, errJSON := ingestResult.FormatJSON(opts)
-			if errJSON != nil {
-				fmt.Fprintf(os.Stderr, "Error formatting JSON output: %v\n", errJSON)
+		var modelProfile *digest.ModelProfile
+		if modelName != "" {
+			modelProfile, err = resolveModelProfile(modelName, modelsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
+		}
 
-			if opts.OutputFile != "" && opts.OutputFile != "-" {
-				if err := writeOutputFile(opts.OutputFile, jsonBytes); err != nil {
-					fmt.Fprintf(os.Stderr, "Error writing to output file %s: %v\n", opts.OutputFile, err)
-					os.Exit(1)
-				}
-				fmt.Fprintf(os.Stderr, "Digest written to: %s\n", opts.OutputFile)
-			} else {
-				os.Stdout.Write(jsonBytes)
+		var secretsAllowlist *digest.SecretsAllowlist
+		if secretsAllow != "" {
+			secretsAllowlist, err = digest.LoadSecretsAllowlist(secretsAllow)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
-		} else {
-			ingestResult.FormatOutput(opts)
+		}
+
+		var piiRules []digest.PIIRule
+		if piiConfig != "" {
+			piiRules, err = digest.LoadPIIRules(piiConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			redactPII = true
+		}
+
+		var licenseText string
+		if licenseTemplate != "" {
+			licenseText, err = digest.LoadLicenseTemplate(licenseTemplate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			stripLicenses = true
+		}
+
+		source := args[0]
+
+		opts := digest.IngestionOptions{
+			Source:              source,
+			OutputFile:          outputFile,
+			MaxFileSize:         maxFileSize,
+			ExcludePatterns:     excludePatterns,
+			IncludePatterns:     includePatterns,
+			Branch:              branch,
+			TruncateLarge:       truncateSpec,
+			Order:               order,
+			PriorityPatterns:    priority,
+			Model:               modelProfile,
+			LineNumbers:         lineNumbers,
+			StripComments:       stripComments,
+			KeepDocComments:     keepDocComments,
+			StripLicenseHeaders: stripLicenses,
+			LicenseTemplate:     licenseText,
+			Outline:             outlineLangs,
+			OutlineKeep:         outlineKeep,
+			Compact:             compact,
+			CompactTabWidth:     compactTabs,
+			Secrets:             secretsMode,
+			SecretsAllowlist:    secretsAllowlist,
+			RedactPII:           redactPII,
+			PIIRules:            piiRules,
+			Dedupe:              dedupe,
+			Deps:                listDeps,
+		}
+
+		if err := digest.CheckLineNumbers(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
-			if opts.OutputFile != "" && opts.OutputFile != "-" {
-				textBytes := []byte(ingestResult.TreeStructure + "\n" + ingestResult.FileContents)
-				if err := writeOutputFile(opts.OutputFile, textBytes); err != nil {
-					fmt.Fprintf(os.Stderr, "Error writing to output file %s: %v\n", opts.OutputFile, err)
-					os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Processing source: %s\n", opts.Source)
+		if opts.Branch != "" {
+			fmt.Fprintf(os.Stderr, "Targeting branch: %s\n", opts.Branch)
+		}
+
+		var ingestResult *digest.Result
+		if templateFile == "" && outputFormat == "ndjson" && digest.CanStreamNDJSON(opts) {
+			// Records are written while the source is read, so ingestion
+			// errors surface from inside the write.
+			writeDigest(opts.OutputFile, func(w io.Writer) error {
+				r, err := digest.StreamNDJSON(w, opts)
+				if r == nil && err != nil {
+					return processingError{err}
 				}
-				fmt.Fprintf(os.Stderr, "Digest written to: %s\n", opts.OutputFile)
-			} else {
-				fmt.Println(ingestResult.TreeStructure)
-				fmt.Println(ingestResult.FileContents)
+				ingestResult = r
+				return err
+			})
+		} else {
+			var err error
+			ingestResult, err = digest.ProcessSource(opts)
+			var secretsErr *digest.SecretsError
+			if errors.As(err, &secretsErr) {
+				fmt.Fprintln(os.Stderr, "\n--- Secrets ---")
+				fmt.Fprint(os.Stderr, digest.FormatSecretsReport(secretsErr.Findings, opts.Secrets))
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error processing source: %v\n", err)
+				os.Exit(1)
 			}
 
+			// Route to the correct formatter — exactly one call
+			writeDigest(opts.OutputFile, func(w io.Writer) error {
+				return digest.Render(formatter, w, ingestResult, opts)
+			})
+		}
+
+		if findings := ingestResult.SecretFindings(); len(findings) > 0 {
+			fmt.Fprintln(os.Stderr, "\n--- Secrets ---")
+			fmt.Fprint(os.Stderr, digest.FormatSecretsReport(findings, opts.Secrets))
+		}
+
+		if redactions := ingestResult.PIIRedactions(); len(redactions) > 0 {
+			fmt.Fprintln(os.Stderr, "\n--- PII ---")
+			fmt.Fprint(os.Stderr, digest.FormatPIIReport(redactions))
+		}
+
+		if ingestResult.Summary != "" {
 			fmt.Fprintln(os.Stderr, "\n--- Summary ---")
 			fmt.Fprint(os.Stderr, ingestResult.Summary)
+		} else if fit := digest.FormatModelFit(ingestResult, opts); fit != "" {
+			fmt.Fprintln(os.Stderr, "\n--- Model Fit ---")
+			fmt.Fprint(os.Stderr, fit)
 		}
-
 	},
 }
 
@@ -152,14 +283,81 @@ var versionCmd = &cobra.Command{
 	},
 }
 
-func writeOutputFile(path string, data []byte) error {
+func resolveModelProfile(name, path string) (*digest.ModelProfile, error) {
+	required := path != ""
+	if path == "" {
+		path = digest.DefaultModelsFilePath()
+	}
+	profiles, err := digest.LoadModelProfiles(path, required)
+	if err != nil {
+		return nil, err
+	}
+	return digest.FindModelProfile(profiles, name)
+}
+
+// processingError marks an ingestion error returned from a write callback,
+// as opposed to an error writing the output.
+type processingError struct{ err error }
+
+func (e processingError) Error() string { return e.err.Error() }
+
+// writeDigest runs write against the output file at path, or against stdout
+// when path is empty or "-". A processingError removes the partial output
+// file.
+func writeDigest(path string, write func(io.Writer) error) {
+	toStdout := path == "" || path == "-"
+	var err error
+	if toStdout {
+		bw := bufio.NewWriter(os.Stdout)
+		err = write(bw)
+		if err == nil {
+			err = bw.Flush()
+		}
+	} else {
+		err = writeOutputFile(path, write)
+	}
+
+	var procErr processingError
+	switch {
+	case errors.As(err, &procErr):
+		if !toStdout {
+			os.Remove(path)
+		}
+		fmt.Fprintf(os.Stderr, "Error processing source: %v\n", procErr.err)
+		os.Exit(1)
+	case err != nil && toStdout:
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error writing to output file %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if !toStdout {
+		fmt.Fprintf(os.Stderr, "Digest written to: %s\n", path)
+	}
+}
+
+func writeOutputFile(path string, write func(io.Writer) error) error {
 	outputDir := filepath.Dir(path)
 	if outputDir != "." && outputDir != "" {
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
 			return err
 		}
 	}
-	return os.WriteFile(path, data, 0644)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if err := write(bw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func Execute() {
@@ -172,11 +370,33 @@ func Execute() {
 func init() {
 	rootCmd.AddCommand(versionCmd)
 
-	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "pathdigest_digest.txt", "Output file path")
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path, or - for stdout (default pathdigest_digest with the extension of the format)")
 	rootCmd.Flags().Int64VarP(&maxFileSize, "max-size", "s", 10*1024*1024, "Maximum file size to process in bytes (e.g., 10485760 for 10MB)") // 10MB default
 
+	rootCmd.Flags().StringVar(&truncateLarge, "truncate-large", "", "Keep the head/tail of files over --max-size instead of dropping them (e.g., head:200,tail:50)")
+
 	rootCmd.Flags().StringSliceP("exclude-pattern", "e", []string{}, "Comma-separated glob patterns to exclude (adds to defaults)")
 	rootCmd.Flags().StringSliceVarP(&includePatterns, "include-pattern", "i", []string{}, "Comma-separated glob patterns to include (overrides excludes)")
 	rootCmd.Flags().StringVarP(&branch, "branch", "b", "", "Branch to clone and ingest (if source is a Git URL)")
-	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format: text or json")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format: "+strings.Join(digest.FormatterNames(), ", "))
+	rootCmd.Flags().StringVar(&templateFile, "template", "", "Render the digest through a Go text/template file (overrides --format)")
+	rootCmd.Flags().BoolVar(&lineNumbers, "line-numbers", false, "Prefix file content with line numbers (adds a lines array in JSON)")
+	rootCmd.Flags().BoolVar(&stripComments, "strip-comments", false, "Remove comments from supported languages")
+	rootCmd.Flags().BoolVar(&keepDocComments, "keep-doc-comments", false, "With --strip-comments, keep documentation comments")
+	rootCmd.Flags().BoolVar(&stripLicenses, "strip-license-headers", false, "Remove license and copyright comment blocks from the top of files")
+	rootCmd.Flags().StringVar(&licenseTemplate, "license-template", "", "File with your license header text to strip (implies --strip-license-headers)")
+	rootCmd.Flags().StringVar(&secretsMode, "secrets", digest.SecretsWarn, "Secret scanning: "+strings.Join(digest.SecretsModes, ", "))
+	rootCmd.Flags().StringVar(&secretsAllow, "secrets-allowlist", "", "File of allowed secrets (sha256:, path: and regex: entries)")
+	rootCmd.Flags().BoolVar(&redactPII, "redact-pii", false, "Replace emails, phone numbers, card numbers, SSNs and IP addresses with placeholders")
+	rootCmd.Flags().StringVar(&piiConfig, "pii-config", "", "JSON file of custom PII rules and disabled built-ins (implies --redact-pii)")
+	rootCmd.Flags().BoolVar(&listDeps, "deps", false, "Add a Dependencies section listing direct dependencies from manifests and lockfiles")
+	rootCmd.Flags().BoolVar(&dedupe, "dedupe", false, "Include the content of identical files once and point later copies at the first")
+	rootCmd.Flags().BoolVar(&compact, "compact", false, "Collapse blank lines, trim trailing whitespace and normalize line endings")
+	rootCmd.Flags().IntVar(&compactTabs, "compact-tabs", 0, "With --compact, convert leading indentation to tabs of this many columns")
+	rootCmd.Flags().StringSliceVar(&outlineLangs, "outline", []string{}, "Reduce files in these languages to declarations and signatures: "+strings.Join(digest.OutlineLanguages(), ", "))
+	rootCmd.Flags().StringSliceVar(&outlineKeep, "outline-keep", []string{}, "Comma-separated glob patterns of files that keep full content with --outline")
+	rootCmd.Flags().StringVar(&order, "order", digest.OrderAlphabetical, "Content order: "+strings.Join(digest.OrderStrategies, ", "))
+	rootCmd.Flags().StringVar(&modelName, "model", "", "Report context fit and estimated input cost for this model (e.g., claude-sonnet-4)")
+	rootCmd.Flags().StringVar(&modelsFile, "models-file", "", "JSON file extending the built-in model profiles (default: <config dir>/pathdigest/models.json)")
+	rootCmd.Flags().StringSliceVar(&priority, "priority", []string{}, "Comma-separated glob patterns moved to the front of the content section")
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ga1az/pathdigest/internal/digest"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the JSON output format",
+	Long: `schema prints the versioned JSON Schema that describes the output of
+--format json. It is generated from the same Go types that produce the output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := digest.FormatJSONSchema()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Check a JSON digest against the JSON Schema",
+	Long: `validate checks a digest produced with --format json (use "-" to read from
+stdin) against the schema printed by "pathdigest schema" and lists every
+violation it finds.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		name := args[0]
+		if name == "-" {
+			name = "stdin"
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading digest: %v\n", err)
+			os.Exit(1)
+		}
+
+		violations, err := digest.ValidateJSONDigest(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error validating digest: %v\n", err)
+			os.Exit(1)
+		}
+		if len(violations) > 0 {
+			for _, v := range violations {
+				fmt.Fprintln(os.Stderr, v.Error())
+			}
+			fmt.Fprintf(os.Stderr, "\n%s is not a valid schema version %d digest (%d problems)\n", name, digest.JSONSchemaVersion, len(violations))
+			os.Exit(1)
+		}
+		fmt.Printf("%s is a valid schema version %d digest\n", name, digest.JSONSchemaVersion)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,266 @@
+// Package binmeta describes common binary files, such as images, archives
+// and executables, for digests that cannot include their content.
+package binmeta
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxListed is the number of archive entries named in a description.
+const maxListed = 10
+
+// A tar listing stops after maxCounted entries or, for a compressed
+// archive, maxTarBytes of decompressed data, so that a small file cannot
+// expand into unbounded work. The count is then reported as a lower bound.
+const (
+	maxCounted  = 1000
+	maxTarBytes = 64 << 20
+)
+
+// Describe returns a one-line description of the file at path, such as
+// "PNG image, 640x480". ok is false when the format is not recognized or the
+// file cannot be parsed.
+func Describe(path string) (desc string, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")),
+		bytes.HasPrefix(head, []byte("\xff\xd8\xff")),
+		bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return describeImage(f)
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return describeZip(path)
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return describeTar(f, "tar archive", false)
+	case bytes.HasPrefix(head, []byte("\x1f\x8b")):
+		return describeTar(f, "gzip-compressed tar archive", true)
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return describeELF(f)
+	case isMachO(head):
+		return describeMachO(f)
+	case bytes.HasPrefix(head, []byte("\xca\xfe\xba\xbe")):
+		// Also the magic of Java class files, which NewFatFile rejects.
+		return describeFatMachO(f)
+	}
+	return "", false
+}
+
+func describeImage(f *os.File) (string, bool) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", false
+	}
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s image, %dx%d", strings.ToUpper(format), cfg.Width, cfg.Height), true
+}
+
+func describeZip(path string) (string, bool) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return "", false
+	}
+	defer r.Close()
+
+	kind := "zip archive"
+	var names []string
+	for _, f := range r.File {
+		if f.Name == "META-INF/MANIFEST.MF" {
+			kind = "JAR archive"
+		}
+		if !strings.HasSuffix(f.Name, "/") {
+			names = append(names, f.Name)
+		}
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jar", ".war", ".ear":
+		kind = "JAR archive"
+	}
+	return kind + ", " + listEntries(names, false), true
+}
+
+func describeTar(f *os.File, kind string, gzipped bool) (string, bool) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", false
+	}
+	var r io.Reader = f
+	var limited *io.LimitedReader
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return "", false
+		}
+		defer gz.Close()
+		limited = &io.LimitedReader{R: gz, N: maxTarBytes}
+		r = limited
+	}
+
+	tr := tar.NewReader(r)
+	var names []string
+	partial := false
+	for {
+		hdr, err := tr.Next()
+		if limited != nil && limited.N <= 0 {
+			partial = true
+			break
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Plain gzip data is not a tar stream; a damaged archive still
+			// gets the entries read so far.
+			if len(names) == 0 {
+				return "", false
+			}
+			break
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if len(names) == maxCounted {
+			partial = true
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	if partial && len(names) == 0 {
+		return "", false
+	}
+	return kind + ", " + listEntries(names, partial), true
+}
+
+// listEntries formats names as "3 files: a, b, c", naming at most maxListed.
+// When partial, names are only the first entries and the counts read
+// "1000+ files" and "and 990+ more".
+func listEntries(names []string, partial bool) string {
+	if len(names) == 0 {
+		return "empty"
+	}
+	plus := ""
+	if partial {
+		plus = "+"
+	}
+	s := fmt.Sprintf("%d%s files: %s", len(names), plus, strings.Join(names[:min(len(names), maxListed)], ", "))
+	if len(names) > maxListed {
+		s += fmt.Sprintf(", and %d%s more", len(names)-maxListed, plus)
+	}
+	return s
+}
+
+var elfTypes = map[elf.Type]string{
+	elf.ET_REL:  "relocatable",
+	elf.ET_EXEC: "executable",
+	elf.ET_DYN:  "shared object",
+	elf.ET_CORE: "core file",
+}
+
+var elfMachines = map[elf.Machine]string{
+	elf.EM_386:     "x86",
+	elf.EM_X86_64:  "x86-64",
+	elf.EM_ARM:     "arm",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_RISCV:   "riscv",
+	elf.EM_PPC64:   "ppc64",
+	elf.EM_S390:    "s390",
+	elf.EM_MIPS:    "mips",
+}
+
+func describeELF(f *os.File) (string, bool) {
+	ef, err := elf.NewFile(f)
+	if err != nil {
+		return "", false
+	}
+	bits := "32-bit"
+	if ef.Class == elf.ELFCLASS64 {
+		bits = "64-bit"
+	}
+	return fmt.Sprintf("ELF %s %s, %s", bits,
+		nameOr(elfTypes[ef.Type], ef.Type.String()),
+		nameOr(elfMachines[ef.Machine], ef.Machine.String())), true
+}
+
+var machoTypes = map[macho.Type]string{
+	macho.TypeObj:    "object file",
+	macho.TypeExec:   "executable",
+	macho.TypeDylib:  "dynamic library",
+	macho.TypeBundle: "bundle",
+}
+
+var machoCPUs = map[macho.Cpu]string{
+	macho.Cpu386:   "x86",
+	macho.CpuAmd64: "x86-64",
+	macho.CpuArm:   "arm",
+	macho.CpuArm64: "arm64",
+	macho.CpuPpc:   "ppc",
+	macho.CpuPpc64: "ppc64",
+}
+
+func isMachO(head []byte) bool {
+	for _, magic := range []string{"\xfe\xed\xfa\xce", "\xce\xfa\xed\xfe", "\xfe\xed\xfa\xcf", "\xcf\xfa\xed\xfe"} {
+		if bytes.HasPrefix(head, []byte(magic)) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeMachO(f *os.File) (string, bool) {
+	mf, err := macho.NewFile(f)
+	if err != nil {
+		return "", false
+	}
+	bits := "32-bit"
+	if mf.Magic == macho.Magic64 {
+		bits = "64-bit"
+	}
+	return fmt.Sprintf("Mach-O %s %s, %s", bits,
+		nameOr(machoTypes[mf.Type], mf.Type.String()),
+		nameOr(machoCPUs[mf.Cpu], mf.Cpu.String())), true
+}
+
+func describeFatMachO(f *os.File) (string, bool) {
+	ff, err := macho.NewFatFile(f)
+	if err != nil {
+		return "", false
+	}
+	cpus := make([]string, len(ff.Arches))
+	for i, arch := range ff.Arches {
+		cpus[i] = nameOr(machoCPUs[arch.Cpu], arch.Cpu.String())
+	}
+	kind := "binary"
+	if len(ff.Arches) > 0 {
+		kind = nameOr(machoTypes[ff.Arches[0].Type], ff.Arches[0].Type.String())
+	}
+	return fmt.Sprintf("Mach-O universal %s, %s", kind, strings.Join(cpus, ", ")), true
+}
+
+func nameOr(name, fallback string) string {
+	if name != "" {
+		return name
+	}
+	return fallback
+}
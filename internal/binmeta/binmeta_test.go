@@ -0,0 +1,170 @@
+package binmeta
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDescribe_Images(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	var pngBuf, jpegBuf, gifBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegBuf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&gifBuf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"PNG image, 32x16":  writeFile(t, "a.png", pngBuf.Bytes()),
+		"JPEG image, 32x16": writeFile(t, "a.jpg", jpegBuf.Bytes()),
+		"GIF image, 32x16":  writeFile(t, "a.gif", gifBuf.Bytes()),
+	}
+	for want, path := range tests {
+		if got, ok := Describe(path); !ok || got != want {
+			t.Errorf("Describe(%s) = %q, %v; want %q", filepath.Base(path), got, ok, want)
+		}
+	}
+}
+
+func TestDescribe_Archives(t *testing.T) {
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for _, name := range []string{"META-INF/", "META-INF/MANIFEST.MF", "com/acme/App.class"} {
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	for i := 0; i < 12; i++ {
+		name := string(rune('a'+i)) + ".txt"
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte("x"))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var tgzBuf bytes.Buffer
+	gz := gzip.NewWriter(&tgzBuf)
+	gz.Write(tarBuf.Bytes())
+	gz.Close()
+
+	var gzBuf bytes.Buffer
+	gz = gzip.NewWriter(&gzBuf)
+	gz.Write([]byte("just some compressed text"))
+	gz.Close()
+
+	tarList := "12 files: a.txt, b.txt, c.txt, d.txt, e.txt, f.txt, g.txt, h.txt, i.txt, j.txt, and 2 more"
+	tests := []struct {
+		path string
+		want string
+	}{
+		{writeFile(t, "app.zip", zipBuf.Bytes()), "JAR archive, 2 files: META-INF/MANIFEST.MF, com/acme/App.class"},
+		{writeFile(t, "files.tar", tarBuf.Bytes()), "tar archive, " + tarList},
+		{writeFile(t, "files.tgz", tgzBuf.Bytes()), "gzip-compressed tar archive, " + tarList},
+		{writeFile(t, "notes.gz", gzBuf.Bytes()), ""},
+		{writeFile(t, "random.bin", []byte{0, 1, 2, 3}), ""},
+	}
+	for _, tt := range tests {
+		got, ok := Describe(tt.path)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Describe(%s) = %q, %v; want %q", filepath.Base(tt.path), got, ok, tt.want)
+		}
+	}
+}
+
+func TestDescribe_TarLimits(t *testing.T) {
+	tarOf := func(n int) []byte {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for i := 0; i < n; i++ {
+			if err := tw.WriteHeader(&tar.Header{Name: fmt.Sprintf("f%04d", i), Mode: 0644}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	names := "f0000, f0001, f0002, f0003, f0004, f0005, f0006, f0007, f0008, f0009"
+
+	// A gzip bomb: one large entry of zeros compresses to a few kilobytes.
+	var bomb bytes.Buffer
+	gz := gzip.NewWriter(&bomb)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "zeros.bin", Mode: 0644, Size: maxTarBytes + 1})
+	zeros := make([]byte, 1<<20)
+	for written := int64(0); written <= maxTarBytes; written += int64(len(zeros)) {
+		tw.Write(zeros[:min(int64(len(zeros)), maxTarBytes+1-written)])
+	}
+	tw.WriteHeader(&tar.Header{Name: "after.txt", Mode: 0644})
+	tw.Close()
+	gz.Close()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{writeFile(t, "exact.tar", tarOf(maxCounted)), fmt.Sprintf("tar archive, %d files: %s, and %d more", maxCounted, names, maxCounted-maxListed)},
+		{writeFile(t, "many.tar", tarOf(maxCounted+5)), fmt.Sprintf("tar archive, %d+ files: %s, and %d+ more", maxCounted, names, maxCounted-maxListed)},
+		{writeFile(t, "bomb.tgz", bomb.Bytes()), "gzip-compressed tar archive, 1+ files: zeros.bin"},
+	}
+	for _, tt := range tests {
+		got, ok := Describe(tt.path)
+		if got != tt.want || !ok {
+			t.Errorf("Describe(%s) = %q, %v; want %q", filepath.Base(tt.path), got, ok, tt.want)
+		}
+	}
+}
+
+func TestDescribe_Executable(t *testing.T) {
+	var prefix string
+	switch runtime.GOOS {
+	case "darwin":
+		prefix = "Mach-O "
+	case "linux", "freebsd", "netbsd", "openbsd":
+		prefix = "ELF "
+	default:
+		t.Skipf("no executable format check for %s", runtime.GOOS)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	got, ok := Describe(exe)
+	if !ok || !strings.HasPrefix(got, prefix) {
+		t.Errorf("Describe(test binary) = %q, %v; want prefix %q", got, ok, prefix)
+	}
+}
@@ -0,0 +1,83 @@
+package deps
+
+import "strings"
+
+// parseCargoToml reads the dependency tables of a Cargo.toml, including
+// target-specific ones, and resolves them with the nearest Cargo.lock.
+// Path, git and workspace dependencies keep that source as their
+// constraint.
+func parseCargoToml(root, rel string, data []byte) ([]Dependency, string, error) {
+	var deps []Dependency
+	// Dependencies written as [dependencies.name] tables.
+	detailed := make(map[string]int)
+	for _, e := range parseTOML(string(data)) {
+		section, name := cargoSection(e.Table)
+		if section == "" {
+			continue
+		}
+		dev := section != "dependencies"
+		if name != "" {
+			i, ok := detailed[e.Table]
+			if !ok {
+				i = len(deps)
+				detailed[e.Table] = i
+				deps = append(deps, Dependency{Name: name, Dev: dev})
+			}
+			if deps[i].Constraint == "" || e.Key == "version" {
+				deps[i].Constraint = cargoConstraint(map[string]string{e.Key: e.Value})
+			}
+			continue
+		}
+
+		dep := Dependency{Name: e.Key, Dev: dev}
+		if s, ok := tomlString(e.Value); ok {
+			dep.Constraint = s
+		} else {
+			dep.Constraint = cargoConstraint(tomlInlineTable(e.Value))
+		}
+		deps = append(deps, dep)
+	}
+	sortDependencies(deps)
+
+	lock, lockData := findLockfile(root, rel, "Cargo.lock")
+	if lock == "" || len(deps) == 0 {
+		return deps, "", nil
+	}
+	versions := lockPackageVersions(lockData, func(s string) string { return s })
+	for i := range deps {
+		deps[i].Version = versions[deps[i].Name]
+	}
+	return deps, lock, nil
+}
+
+// cargoSection splits a table header such as "dev-dependencies",
+// "target.cfg(unix).dependencies" or "dependencies.serde" into the kind of
+// dependency table and, for the last form, the dependency name. section is
+// empty for other tables.
+func cargoSection(table string) (section, name string) {
+	parts := strings.Split(table, ".")
+	if parts[0] == "target" && len(parts) >= 3 {
+		parts = parts[2:]
+	}
+	switch parts[0] {
+	case "dependencies", "dev-dependencies", "build-dependencies":
+	default:
+		return "", ""
+	}
+	if len(parts) > 1 {
+		name = strings.Join(parts[1:], ".")
+	}
+	return parts[0], name
+}
+
+func cargoConstraint(fields map[string]string) string {
+	if v, ok := tomlString(fields["version"]); ok {
+		return v
+	}
+	for _, source := range []string{"path", "git", "workspace"} {
+		if _, ok := fields[source]; ok {
+			return source
+		}
+	}
+	return ""
+}
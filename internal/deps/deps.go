@@ -0,0 +1,118 @@
+// Package deps reads dependency manifests and lockfiles into a list of the
+// direct dependencies of a project.
+package deps
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dependency is a direct dependency declared in a manifest.
+type Dependency struct {
+	Name string
+	// Constraint is the version requirement as declared, such as "^1.2.0".
+	Constraint string
+	// Version is the resolved version, from the manifest itself when it
+	// pins one or from a lockfile; empty when unknown.
+	Version string
+	// Dev marks development, test and build-only dependencies.
+	Dev bool
+}
+
+// Manifest is the parsed content of one manifest file.
+type Manifest struct {
+	// Path is the slash-separated path of the manifest below the root.
+	Path      string
+	Ecosystem string
+	// Lockfile is the path of the lockfile that resolved versions, if any.
+	Lockfile     string
+	Dependencies []Dependency
+	Err          error
+}
+
+// parsers read one kind of manifest. root is the directory being scanned
+// and rel the manifest's path below it; lockfiles are looked up from the
+// manifest's directory up to root.
+var parsers = []struct {
+	match     func(name string) bool
+	ecosystem string
+	parse     func(root, rel string, data []byte) ([]Dependency, string, error)
+}{
+	{func(n string) bool { return n == "go.mod" }, "go", parseGoMod},
+	{func(n string) bool { return n == "package.json" }, "npm", parsePackageJSON},
+	{func(n string) bool { return strings.HasPrefix(n, "requirements") && strings.HasSuffix(n, ".txt") }, "pypi", parseRequirements},
+	{func(n string) bool { return n == "pyproject.toml" }, "pypi", parsePyproject},
+	{func(n string) bool { return n == "Cargo.toml" }, "cargo", parseCargoToml},
+}
+
+// Find walks root and parses every manifest it finds, in path order. skip
+// reports whether a file or directory, given by its slash path below root,
+// is left out. Manifests without dependencies are omitted.
+func Find(root string, skip func(rel string, isDir bool) bool) []Manifest {
+	var manifests []Manifest
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, errRel := filepath.Rel(root, p)
+		if errRel != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if skip != nil && skip(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		for _, parser := range parsers {
+			if !parser.match(d.Name()) {
+				continue
+			}
+			m := Manifest{Path: rel, Ecosystem: parser.ecosystem}
+			data, errRead := os.ReadFile(p)
+			if errRead == nil {
+				m.Dependencies, m.Lockfile, m.Err = parser.parse(root, rel, data)
+			} else {
+				m.Err = errRead
+			}
+			if len(m.Dependencies) > 0 || m.Err != nil {
+				manifests = append(manifests, m)
+			}
+		}
+		return nil
+	})
+	return manifests
+}
+
+// findLockfile returns the slash path below root and the content of the
+// first of names found in the directory of rel or one of its parents.
+func findLockfile(root, rel string, names ...string) (string, []byte) {
+	for dir := path.Dir(rel); ; dir = path.Dir(dir) {
+		for _, name := range names {
+			lock := path.Join(dir, name)
+			if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(lock))); err == nil {
+				return lock, data
+			}
+		}
+		if dir == "." {
+			return "", nil
+		}
+	}
+}
+
+func sortDependencies(deps []Dependency) {
+	sort.SliceStable(deps, func(i, j int) bool {
+		if deps[i].Dev != deps[j].Dev {
+			return !deps[i].Dev
+		}
+		return deps[i].Name < deps[j].Name
+	})
+}
@@ -0,0 +1,218 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFind(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.23
+
+require github.com/spf13/cobra v1.8.0 // pinned
+
+require (
+	golang.org/x/text v0.14.0
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+)
+`,
+		"web/package.json": `{
+  "dependencies": {"react": "^18.2.0", "@babel/core": "^7.0.0"},
+  "devDependencies": {"typescript": "~5.4.0"}
+}`,
+		"web/package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "web"},
+    "node_modules/react": {"version": "18.3.1"},
+    "node_modules/@babel/core": {"version": "7.24.5"},
+    "node_modules/typescript": {"version": "5.4.5"}
+  }
+}`,
+		"ui/package.json": `{"dependencies": {"lodash": "^4.17.0"}}`,
+		"ui/yarn.lock": `# yarn lockfile v1
+
+"lodash@^4.17.0", lodash@^4.17.21:
+  version "4.17.21"
+  resolved "https://registry.yarnpkg.com/lodash/-/lodash-4.17.21.tgz"
+`,
+		"node_modules/left-pad/package.json": `{"dependencies": {"x": "1"}}`,
+		"requirements-dev.txt": `# tools
+-r requirements.txt
+pytest==8.1.1
+black>=24 ; python_version >= "3.9"
+-e git+https://github.com/acme/lib.git#egg=lib
+`,
+		"svc/pyproject.toml": `[project]
+name = "svc"
+dependencies = [
+    "requests[socks]>=2.31",  # http
+    "Flask_Login",
+]
+
+[dependency-groups]
+dev = ["ruff==0.4.2"]
+`,
+		"svc/uv.lock": `version = 1
+
+[[package]]
+name = "requests"
+version = "2.31.0"
+
+[[package]]
+name = "flask-login"
+version = "0.6.3"
+`,
+		"poetry/pyproject.toml": `[tool.poetry.dependencies]
+python = "^3.11"
+django = "^5.0"
+celery = { version = "^5.3", extras = ["redis"] }
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.0"
+`,
+		"crate/Cargo.toml": `[package]
+name = "crate"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+anyhow = "1"
+local = { path = "../local" }
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+
+[dev-dependencies.tokio]
+version = "1.37"
+features = ["full"]
+`,
+		"crate/Cargo.lock": `version = 3
+
+[[package]]
+name = "anyhow"
+version = "1.0.82"
+
+[[package]]
+name = "serde"
+version = "1.0.200"
+
+[[package]]
+name = "tokio"
+version = "1.37.0"
+`,
+	})
+
+	manifests := Find(dir, func(rel string, isDir bool) bool { return isDir && rel == "node_modules" })
+	got := make(map[string]Manifest)
+	var paths []string
+	for _, m := range manifests {
+		if m.Err != nil {
+			t.Errorf("%s: %v", m.Path, m.Err)
+		}
+		got[m.Path] = m
+		paths = append(paths, m.Path)
+	}
+	wantPaths := []string{"crate/Cargo.toml", "go.mod", "poetry/pyproject.toml", "requirements-dev.txt", "svc/pyproject.toml", "ui/package.json", "web/package.json"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Fatalf("manifests = %v, want %v", paths, wantPaths)
+	}
+
+	tests := []struct {
+		path     string
+		lockfile string
+		want     []Dependency
+	}{
+		{"go.mod", "", []Dependency{
+			{Name: "github.com/spf13/cobra", Constraint: "v1.8.0", Version: "v1.8.0"},
+			{Name: "golang.org/x/text", Constraint: "v0.14.0", Version: "v0.14.0"},
+		}},
+		{"web/package.json", "web/package-lock.json", []Dependency{
+			{Name: "@babel/core", Constraint: "^7.0.0", Version: "7.24.5"},
+			{Name: "react", Constraint: "^18.2.0", Version: "18.3.1"},
+			{Name: "typescript", Constraint: "~5.4.0", Version: "5.4.5", Dev: true},
+		}},
+		{"ui/package.json", "ui/yarn.lock", []Dependency{
+			{Name: "lodash", Constraint: "^4.17.0", Version: "4.17.21"},
+		}},
+		{"requirements-dev.txt", "", []Dependency{
+			{Name: "black", Constraint: ">=24"},
+			{Name: "pytest", Constraint: "==8.1.1", Version: "8.1.1"},
+		}},
+		{"svc/pyproject.toml", "svc/uv.lock", []Dependency{
+			{Name: "Flask_Login", Version: "0.6.3"},
+			{Name: "requests", Constraint: ">=2.31", Version: "2.31.0"},
+			{Name: "ruff", Constraint: "==0.4.2", Version: "0.4.2", Dev: true},
+		}},
+		{"poetry/pyproject.toml", "", []Dependency{
+			{Name: "celery", Constraint: "^5.3"},
+			{Name: "django", Constraint: "^5.0"},
+			{Name: "pytest", Constraint: "^8.0", Dev: true},
+		}},
+		{"crate/Cargo.toml", "crate/Cargo.lock", []Dependency{
+			{Name: "anyhow", Constraint: "1", Version: "1.0.82"},
+			{Name: "libc", Constraint: "0.2"},
+			{Name: "local", Constraint: "path"},
+			{Name: "serde", Constraint: "1.0", Version: "1.0.200"},
+			{Name: "tokio", Constraint: "1.37", Version: "1.37.0", Dev: true},
+		}},
+	}
+	for _, tt := range tests {
+		m := got[tt.path]
+		if m.Lockfile != tt.lockfile {
+			t.Errorf("%s: lockfile = %q, want %q", tt.path, m.Lockfile, tt.lockfile)
+		}
+		if !reflect.DeepEqual(m.Dependencies, tt.want) {
+			t.Errorf("%s: dependencies =\n%+v\nwant\n%+v", tt.path, m.Dependencies, tt.want)
+		}
+	}
+}
+
+func TestFind_InvalidManifest(t *testing.T) {
+	dir := writeTree(t, map[string]string{"package.json": "{not json"})
+	manifests := Find(dir, nil)
+	if len(manifests) != 1 || manifests[0].Err == nil || !strings.Contains(manifests[0].Err.Error(), "invalid package.json") {
+		t.Errorf("manifests = %+v, want one with a parse error", manifests)
+	}
+}
+
+func TestPackageLockNestedWorkspace(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"packages/app/package.json": `{"dependencies": {"react": "^17.0.0", "lodash": "^4.0.0"}}`,
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {
+			"node_modules/react": {"version": "18.3.1"},
+			"packages/app/node_modules/react": {"version": "17.0.2"},
+			"node_modules/lodash": {"version": "4.17.21"}
+		}}`,
+	})
+	manifests := Find(dir, nil)
+	if len(manifests) != 1 || manifests[0].Lockfile != "package-lock.json" {
+		t.Fatalf("manifests = %+v", manifests)
+	}
+	want := []Dependency{
+		{Name: "lodash", Constraint: "^4.0.0", Version: "4.17.21"},
+		{Name: "react", Constraint: "^17.0.0", Version: "17.0.2"},
+	}
+	if !reflect.DeepEqual(manifests[0].Dependencies, want) {
+		t.Errorf("dependencies = %+v, want %+v", manifests[0].Dependencies, want)
+	}
+}
@@ -0,0 +1,37 @@
+package deps
+
+import "strings"
+
+// parseGoMod reads the require directives of a go.mod file, leaving out
+// indirect dependencies. Their versions are already resolved.
+func parseGoMod(root, rel string, data []byte) ([]Dependency, string, error) {
+	var deps []Dependency
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		if len(fields) >= 2 && !indirect {
+			deps = append(deps, Dependency{Name: fields[0], Constraint: fields[1], Version: fields[1]})
+		}
+	}
+	sortDependencies(deps)
+	return deps, "", nil
+}
@@ -0,0 +1,122 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// parsePackageJSON reads the dependencies, optionalDependencies and
+// devDependencies of a package.json and resolves them with the nearest
+// package-lock.json or yarn.lock.
+func parsePackageJSON(root, rel string, data []byte) ([]Dependency, string, error) {
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, "", fmt.Errorf("invalid package.json: %w", err)
+	}
+
+	var deps []Dependency
+	for _, group := range []map[string]string{pkg.Dependencies, pkg.OptionalDependencies} {
+		for name, constraint := range group {
+			deps = append(deps, Dependency{Name: name, Constraint: constraint})
+		}
+	}
+	for name, constraint := range pkg.DevDependencies {
+		deps = append(deps, Dependency{Name: name, Constraint: constraint, Dev: true})
+	}
+	sortDependencies(deps)
+
+	lock, lockData := findLockfile(root, rel, "package-lock.json", "yarn.lock")
+	var resolve func(name, constraint string) string
+	switch path.Base(lock) {
+	case "package-lock.json":
+		resolve = packageLockResolver(lockData, path.Dir(rel), path.Dir(lock))
+	case "yarn.lock":
+		resolve = yarnLockResolver(lockData)
+	}
+	if resolve == nil {
+		return deps, "", nil
+	}
+	for i := range deps {
+		deps[i].Version = resolve(deps[i].Name, deps[i].Constraint)
+	}
+	return deps, lock, nil
+}
+
+// packageLockResolver looks packages up in a package-lock.json. Version 2
+// and 3 lockfiles key packages by install path, either hoisted to the lock's
+// directory or nested below the manifest's; version 1 keys them by name.
+func packageLockResolver(data []byte, manifestDir, lockDir string) func(name, constraint string) string {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return nil
+	}
+	nested := strings.TrimPrefix(strings.TrimPrefix(manifestDir, lockDir), "/")
+	if lockDir == "." {
+		nested = manifestDir
+	}
+	return func(name, _ string) string {
+		if nested != "." && nested != "" {
+			if p, ok := lock.Packages[nested+"/node_modules/"+name]; ok {
+				return p.Version
+			}
+		}
+		if p, ok := lock.Packages["node_modules/"+name]; ok {
+			return p.Version
+		}
+		return lock.Dependencies[name].Version
+	}
+}
+
+// yarnLockResolver looks packages up in a yarn.lock of either the classic
+// or the Berry format. Entries are keyed by "name@range" specifiers.
+func yarnLockResolver(data []byte) func(name, constraint string) string {
+	bySpec := make(map[string]string)
+	byName := make(map[string]string)
+	var specs []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case !strings.HasPrefix(line, " ") && strings.HasSuffix(line, ":"):
+			specs = specs[:0]
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				specs = append(specs, strings.Trim(strings.TrimSpace(spec), `"`))
+			}
+		case strings.HasPrefix(strings.TrimSpace(line), "version"):
+			version := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "version"))
+			version = strings.Trim(strings.TrimPrefix(version, ":"), ` "`)
+			for _, spec := range specs {
+				bySpec[spec] = version
+				if at := strings.LastIndex(spec, "@"); at > 0 {
+					if _, ok := byName[spec[:at]]; !ok {
+						byName[spec[:at]] = version
+					}
+				}
+			}
+			specs = specs[:0]
+		}
+	}
+	return func(name, constraint string) string {
+		if v, ok := bySpec[name+"@"+constraint]; ok {
+			return v
+		}
+		if v, ok := bySpec[name+"@npm:"+constraint]; ok {
+			return v
+		}
+		return byName[name]
+	}
+}
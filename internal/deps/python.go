@@ -0,0 +1,129 @@
+package deps
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pep508 splits a requirement such as "requests[socks]>=2.31; python_version>'3'"
+// into its name and version constraint.
+var pep508 = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*([^;]*)`)
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName applies the PEP 503 normalization that lockfiles use.
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparators.ReplaceAllString(name, "-"))
+}
+
+func parseRequirement(req string) (Dependency, bool) {
+	m := pep508.FindStringSubmatch(strings.TrimSpace(req))
+	if m == nil {
+		return Dependency{}, false
+	}
+	dep := Dependency{Name: m[1], Constraint: strings.Join(strings.Fields(m[2]), "")}
+	if v, ok := strings.CutPrefix(dep.Constraint, "=="); ok && !strings.ContainsAny(v, ",*") {
+		dep.Version = v
+	}
+	return dep, true
+}
+
+// parseRequirements reads a pip requirements file. Options, includes and
+// editable or URL requirements are skipped; "==" pins are the resolved
+// versions.
+func parseRequirements(root, rel string, data []byte) ([]Dependency, string, error) {
+	var deps []Dependency
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		if dep, ok := parseRequirement(line); ok {
+			deps = append(deps, dep)
+		}
+	}
+	sortDependencies(deps)
+	return deps, "", nil
+}
+
+// parsePyproject reads PEP 621 project dependencies, PEP 735 dependency
+// groups and Poetry dependency tables, and resolves them with the nearest
+// uv.lock or poetry.lock.
+func parsePyproject(root, rel string, data []byte) ([]Dependency, string, error) {
+	var deps []Dependency
+	for _, e := range parseTOML(string(data)) {
+		switch {
+		case e.Table == "project" && e.Key == "dependencies":
+			for _, req := range tomlStrings(e.Value) {
+				if dep, ok := parseRequirement(req); ok {
+					deps = append(deps, dep)
+				}
+			}
+		case e.Table == "dependency-groups":
+			for _, req := range tomlStrings(e.Value) {
+				if dep, ok := parseRequirement(req); ok {
+					dep.Dev = true
+					deps = append(deps, dep)
+				}
+			}
+		case e.Table == "tool.poetry.dependencies" || e.Table == "tool.poetry.dev-dependencies" ||
+			strings.HasPrefix(e.Table, "tool.poetry.group.") && strings.HasSuffix(e.Table, ".dependencies"):
+			if e.Key == "python" {
+				continue
+			}
+			dep := Dependency{Name: e.Key, Dev: e.Table != "tool.poetry.dependencies"}
+			if s, ok := tomlString(e.Value); ok {
+				dep.Constraint = s
+			} else if v, ok := tomlString(tomlInlineTable(e.Value)["version"]); ok {
+				dep.Constraint = v
+			}
+			deps = append(deps, dep)
+		}
+	}
+	sortDependencies(deps)
+
+	lock, lockData := findLockfile(root, rel, "uv.lock", "poetry.lock")
+	if lock == "" || len(deps) == 0 {
+		return deps, "", nil
+	}
+	versions := lockPackageVersions(lockData, normalizePythonName)
+	for i := range deps {
+		if v, ok := versions[normalizePythonName(deps[i].Name)]; ok {
+			deps[i].Version = v
+		}
+	}
+	return deps, lock, nil
+}
+
+// lockPackageVersions maps the names of the [[package]] tables of a TOML
+// lockfile, such as Cargo.lock, poetry.lock or uv.lock, to their versions.
+// When a package is locked at several versions, the first one wins.
+func lockPackageVersions(data []byte, normalize func(string) string) map[string]string {
+	type pkg struct{ name, version string }
+	var pkgs []pkg
+	index := -1
+	for _, e := range parseTOML(string(data)) {
+		if e.Table != "package" || e.Key != "name" && e.Key != "version" {
+			continue
+		}
+		if e.Index != index {
+			pkgs = append(pkgs, pkg{})
+			index = e.Index
+		}
+		if e.Key == "name" {
+			pkgs[len(pkgs)-1].name, _ = tomlString(e.Value)
+		} else {
+			pkgs[len(pkgs)-1].version, _ = tomlString(e.Value)
+		}
+	}
+	out := make(map[string]string)
+	for _, p := range pkgs {
+		if _, seen := out[normalize(p.name)]; !seen && p.name != "" && p.version != "" {
+			out[normalize(p.name)] = p.version
+		}
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package deps
+
+import "strings"
+
+// tomlEntry is one key/value pair of a TOML document. Only what manifests
+// need is supported: tables, arrays of tables, strings, arrays and inline
+// tables, whose values are kept as raw text.
+type tomlEntry struct {
+	// Table is the header of the enclosing table without brackets, such as
+	// "tool.poetry.dependencies"; Index counts arrays of tables, so that
+	// the entries of one [[package]] share it.
+	Table string
+	Index int
+	Key   string
+	Value string
+}
+
+func parseTOML(data string) []tomlEntry {
+	var entries []tomlEntry
+	table, index := "", 0
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "[["):
+			table = tomlTableName(strings.TrimSuffix(strings.TrimPrefix(line, "[["), "]]"))
+			index++
+			continue
+		case strings.HasPrefix(line, "["):
+			table = tomlTableName(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+			continue
+		}
+
+		key, value, ok := splitTOMLKeyValue(line)
+		if !ok {
+			continue
+		}
+		// Arrays and inline tables may continue over several lines.
+		for tomlDepth(value) > 0 && i+1 < len(lines) {
+			i++
+			value += "\n" + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		entries = append(entries, tomlEntry{Table: table, Index: index, Key: key, Value: value})
+	}
+	return entries
+}
+
+// tomlTableName normalizes a table header by unquoting its keys, so that
+// [target.'cfg(unix)'.dependencies] becomes target.cfg(unix).dependencies.
+func tomlTableName(s string) string {
+	return strings.NewReplacer(`"`, "", "'", "", " ", "").Replace(strings.TrimSpace(s))
+}
+
+func splitTOMLKeyValue(line string) (key, value string, ok bool) {
+	eq := -1
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			eq = i
+		}
+		if eq >= 0 {
+			break
+		}
+	}
+	if eq < 0 {
+		return "", "", false
+	}
+	key = strings.Trim(strings.TrimSpace(line[:eq]), `"'`)
+	return key, strings.TrimSpace(line[eq+1:]), true
+}
+
+// stripTOMLComment removes a "#" comment that is not inside a string.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlDepth returns how many brackets and braces of value are still open.
+func tomlDepth(value string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// tomlString returns the content of a string value.
+func tomlString(value string) (string, bool) {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1], true
+	}
+	return "", false
+}
+
+// tomlStrings returns the string elements of an array value.
+func tomlStrings(value string) []string {
+	var out []string
+	for i := 0; i < len(value); i++ {
+		q := value[i]
+		if q != '"' && q != '\'' {
+			continue
+		}
+		end := strings.IndexByte(value[i+1:], q)
+		if end < 0 {
+			break
+		}
+		out = append(out, value[i+1:i+1+end])
+		i += end + 1
+	}
+	return out
+}
+
+// tomlInlineTable returns the keys of an inline table value with their raw
+// values.
+func tomlInlineTable(value string) map[string]string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		return nil
+	}
+	fields := make(map[string]string)
+	inner := value[1 : len(value)-1]
+	start := 0
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) && (inner[i] != ',' || tomlDepth(inner[start:i]) != 0 || strings.Count(inner[start:i], `"`)%2 != 0) {
+			continue
+		}
+		if k, v, ok := splitTOMLKeyValue(strings.TrimSpace(inner[start:i])); ok {
+			fields[k] = v
+		}
+		start = i + 1
+	}
+	return fields
+}
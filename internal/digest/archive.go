@@ -0,0 +1,198 @@
+package digest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+const (
+	manifestName         = "MANIFEST.json"
+	manifestFallbackName = ".pathdigest-MANIFEST.json"
+)
+
+// ArchiveManifest is written next to the bundled files in tar and zip output.
+type ArchiveManifest struct {
+	SchemaVersion int               `json:"schema_version"`
+	Summary       JSONSummary       `json:"summary"`
+	Files         []ManifestFile    `json:"files"`
+	Skipped       []ManifestSkipped `json:"skipped"`
+	GitInfo       *JSONGitInfo      `json:"git_info,omitempty"`
+}
+
+type ManifestFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Mode    string `json:"mode,omitempty"`
+	ModTime string `json:"mtime,omitempty"`
+	SHA256  string `json:"sha256,omitempty"`
+}
+
+// ManifestSkipped is a selected tree entry that has no file in the bundle,
+// such as a non-text, oversized or truncated file.
+type ManifestSkipped struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// bundleWriter adds regular files to an archive.
+type bundleWriter interface {
+	add(name string, mode fs.FileMode, modTime time.Time, data []byte) error
+	Close() error
+}
+
+type tarBundle struct{ tw *tar.Writer }
+
+func (b tarBundle) add(name string, mode fs.FileMode, modTime time.Time, data []byte) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(mode.Perm()),
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+	}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(data)
+	return err
+}
+
+func (b tarBundle) Close() error { return b.tw.Close() }
+
+type zipBundle struct{ zw *zip.Writer }
+
+func (b zipBundle) add(name string, mode fs.FileMode, modTime time.Time, data []byte) error {
+	hdr := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime}
+	hdr.SetMode(mode.Perm())
+	w, err := b.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (b zipBundle) Close() error { return b.zw.Close() }
+
+// WriteTar writes the selected text files and MANIFEST.json as a tar archive.
+func (r *Result) WriteTar(w io.Writer, opts IngestionOptions) error {
+	return r.writeBundle(tarBundle{tw: tar.NewWriter(w)}, opts)
+}
+
+// WriteZip writes the selected text files and MANIFEST.json as a zip archive.
+func (r *Result) WriteZip(w io.Writer, opts IngestionOptions) error {
+	return r.writeBundle(zipBundle{zw: zip.NewWriter(w)}, opts)
+}
+
+// writeBundle stores every ingested text file at its relative path, keeping
+// its permissions and modification time. Files whose content was not read in
+// full are listed in the manifest instead. Content comes from the Result, so
+// bundles also work for Git sources whose clone is already gone.
+func (r *Result) writeBundle(b bundleWriter, opts IngestionOptions) error {
+	manifest := ArchiveManifest{
+		SchemaVersion: JSONSchemaVersion,
+		Summary:       r.jsonSummary(opts),
+		Files:         []ManifestFile{},
+		Skipped:       []ManifestSkipped{},
+		GitInfo:       r.jsonGitInfo(),
+	}
+
+	var newest time.Time
+	used := make(map[string]bool)
+	for _, node := range r.contentNodes(opts) {
+		name := r.bundlePath(node)
+		if node.Type != NodeTypeFile || node.Error != nil {
+			manifest.Skipped = append(manifest.Skipped, skippedEntry(name, node))
+			continue
+		}
+
+		mode := node.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		modTime := node.ModTime
+		if modTime.IsZero() {
+			modTime = time.Now()
+		}
+		if modTime.After(newest) {
+			newest = modTime
+		}
+
+		if err := b.add(name, mode, modTime, []byte(node.Content)); err != nil {
+			b.Close()
+			return err
+		}
+		used[name] = true
+
+		f := ManifestFile{
+			Path:    name,
+			Size:    int64(len(node.Content)),
+			Mode:    jsonFileMode(mode),
+			ModTime: modTime.UTC().Format(time.RFC3339),
+			SHA256:  node.SHA256,
+		}
+		manifest.Files = append(manifest.Files, f)
+	}
+
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		if node.Type == NodeTypeSymlink || node.Type == NodeTypeExcluded {
+			manifest.Skipped = append(manifest.Skipped, skippedEntry(r.bundlePath(node), node))
+		}
+		return true
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		b.Close()
+		return err
+	}
+	name := manifestName
+	if used[name] {
+		name = manifestFallbackName
+	}
+	if newest.IsZero() {
+		newest = time.Now()
+	}
+	if err := b.add(name, 0644, newest, append(data, '\n')); err != nil {
+		b.Close()
+		return err
+	}
+	return b.Close()
+}
+
+// bundlePath is the slash-separated archive path of node. A single-file
+// source is stored under its own name.
+func (r *Result) bundlePath(node *FileNode) string {
+	if node == r.RootNode && node.Type != NodeTypeDir {
+		return node.Name
+	}
+	return filepath.ToSlash(node.Path)
+}
+
+func skippedEntry(name string, node *FileNode) ManifestSkipped {
+	return ManifestSkipped{Path: name, Type: string(node.Type), Reason: exclusionReason(node)}
+}
+
+// exclusionReason explains why node's content is missing or incomplete. It
+// is empty for directories and fully included files.
+func exclusionReason(node *FileNode) string {
+	switch {
+	case node.Error != nil:
+		return node.Error.Error()
+	case node.Type == NodeTypeTruncated:
+		return restorePlaceholderTruncated
+	case node.Type == NodeTypeNotText || node.Type == NodeTypeTooLarge:
+		return string(node.Type) + " - content not included"
+	case node.Type == NodeTypeSymlink:
+		return "symlink not followed"
+	case node.Type == NodeTypeExcluded:
+		return "excluded by pattern"
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package digest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func archiveTestResult() *Result {
+	modTime := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &Result{
+		RootNode: &FileNode{
+			Name: "project", Path: ".", Type: NodeTypeDir,
+			Children: []*FileNode{
+				{Name: "cmd", Path: "cmd", Type: NodeTypeDir, Children: []*FileNode{
+					{Name: "main.go", Path: "cmd/main.go", Type: NodeTypeFile, Content: "package main\n", Mode: 0644, ModTime: modTime, SHA256: "aa"},
+				}},
+				{Name: "run.sh", Path: "run.sh", Type: NodeTypeFile, Content: "#!/bin/sh\n", Mode: 0755, ModTime: modTime},
+				{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 100},
+				{Name: "big.log", Path: "big.log", Type: NodeTypeTruncated, Content: "a\n...\nz\n",
+					Truncation: &Truncation{HeadLines: 1, TailLines: 1, OmittedLines: 5}},
+				{Name: "link", Path: "link", Type: NodeTypeSymlink},
+			},
+		},
+		TotalFiles: 4,
+	}
+}
+
+type archivedFile struct {
+	mode    fs.FileMode
+	modTime time.Time
+	content string
+}
+
+func readTar(t *testing.T, data []byte) map[string]archivedFile {
+	t.Helper()
+	files := make(map[string]archivedFile)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("invalid tar: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		files[hdr.Name] = archivedFile{mode: fs.FileMode(hdr.Mode), modTime: hdr.ModTime, content: string(content)}
+	}
+}
+
+func readZip(t *testing.T, data []byte) map[string]archivedFile {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := make(map[string]archivedFile)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = archivedFile{mode: f.Mode().Perm(), modTime: f.Modified, content: string(content)}
+	}
+	return files
+}
+
+func TestArchiveFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*Result, io.Writer, IngestionOptions) error
+		read  func(*testing.T, []byte) map[string]archivedFile
+	}{
+		{"tar", func(r *Result, w io.Writer, opts IngestionOptions) error { return r.WriteTar(w, opts) }, readTar},
+		{"zip", func(r *Result, w io.Writer, opts IngestionOptions) error { return r.WriteZip(w, opts) }, readZip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(archiveTestResult(), &buf, IngestionOptions{Source: "."}); err != nil {
+				t.Fatalf("write returned error: %v", err)
+			}
+			files := tt.read(t, buf.Bytes())
+
+			if len(files) != 3 {
+				t.Errorf("archive has %d entries, want 3 (two files and the manifest)", len(files))
+			}
+			main := files["cmd/main.go"]
+			if main.content != "package main\n" || main.mode != 0644 {
+				t.Errorf("cmd/main.go = %+v", main)
+			}
+			if !main.modTime.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+				t.Errorf("cmd/main.go mtime = %v", main.modTime)
+			}
+			if files["run.sh"].mode != 0755 {
+				t.Errorf("run.sh mode = %v, want 0755", files["run.sh"].mode)
+			}
+
+			var manifest ArchiveManifest
+			if err := json.Unmarshal([]byte(files[manifestName].content), &manifest); err != nil {
+				t.Fatalf("invalid manifest: %v", err)
+			}
+			if len(manifest.Files) != 2 || manifest.Files[0].Path != "cmd/main.go" || manifest.Files[0].SHA256 != "aa" {
+				t.Errorf("unexpected manifest files: %+v", manifest.Files)
+			}
+			skipped := make(map[string]string)
+			for _, s := range manifest.Skipped {
+				skipped[s.Path] = s.Type
+			}
+			for path, typ := range map[string]string{"logo.png": "non-text", "big.log": "truncated", "link": "symlink"} {
+				if skipped[path] != typ {
+					t.Errorf("skipped[%q] = %q, want %q", path, skipped[path], typ)
+				}
+			}
+		})
+	}
+}
+
+func TestArchive_ManifestNameCollision(t *testing.T) {
+	r := &Result{RootNode: &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: manifestName, Path: manifestName, Type: NodeTypeFile, Content: "mine\n"},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := r.WriteTar(&buf, IngestionOptions{}); err != nil {
+		t.Fatalf("WriteTar returned error: %v", err)
+	}
+	files := readTar(t, buf.Bytes())
+	if files[manifestName].content != "mine\n" {
+		t.Errorf("project's own %s was overwritten", manifestName)
+	}
+	if _, ok := files[manifestFallbackName]; !ok {
+		t.Errorf("manifest not written as %s", manifestFallbackName)
+	}
+}
+
+func TestArchive_SingleFile(t *testing.T) {
+	r := &Result{RootNode: &FileNode{Name: "notes.txt", Path: ".", Type: NodeTypeFile, Content: "hi\n"}}
+	var buf bytes.Buffer
+	if err := r.WriteZip(&buf, IngestionOptions{}); err != nil {
+		t.Fatalf("WriteZip returned error: %v", err)
+	}
+	if got := readZip(t, buf.Bytes())["notes.txt"].content; got != "hi\n" {
+		t.Errorf("notes.txt content = %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package digest
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{"path", "type", "size", "lines", "tokens", "sha256", "reason"}
+
+// WriteCSV writes one comma-separated row per tree node, without content.
+func (r *Result) WriteCSV(w io.Writer, opts IngestionOptions) error {
+	return r.writeDelimited(w, ',')
+}
+
+// WriteTSV is WriteCSV with tab-separated columns.
+func (r *Result) WriteTSV(w io.Writer, opts IngestionOptions) error {
+	return r.writeDelimited(w, '\t')
+}
+
+// writeDelimited lists every tree node in tree order. Directory rows carry
+// the totals of everything below them. encoding/csv quotes any field that
+// contains the separator, quotes or line breaks; neutralizeFormula keeps
+// spreadsheets from evaluating a field.
+func (r *Result) writeDelimited(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	var errWrite error
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		record := []string{
+			r.bundlePath(node),
+			string(node.Type),
+			strconv.FormatInt(node.Size, 10),
+			strconv.Itoa(countLines(node)),
+			strconv.Itoa(countTokens(node)),
+			node.SHA256,
+			exclusionReason(node),
+		}
+		for i, field := range record {
+			record[i] = neutralizeFormula(field)
+		}
+		errWrite = cw.Write(record)
+		return errWrite == nil
+	})
+	if errWrite != nil {
+		return errWrite
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// neutralizeFormula prefixes a field that a spreadsheet would read as a
+// formula, such as a file named "=HYPERLINK(...)", with a single quote.
+func neutralizeFormula(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
@@ -0,0 +1,118 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func csvTestResult() *Result {
+	return &Result{RootNode: &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir, Size: 30,
+		Children: []*FileNode{
+			{Name: "a,\"b\"\nc.txt", Path: "a,\"b\"\nc.txt", Type: NodeTypeFile, Size: 12, Content: "one\ntwo\nsix\n", SHA256: "ff"},
+			{Name: "tab\there.go", Path: "tab\there.go", Type: NodeTypeFile, Size: 8, Content: "package\n"},
+			{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 10},
+		},
+	}}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := csvTestResult().WriteCSV(&buf, IngestionOptions{}); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "package") {
+		t.Error("CSV output contains file content")
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("got %d records, want header + 4 nodes", len(records))
+	}
+	if strings.Join(records[0], ",") != "path,type,size,lines,tokens,sha256,reason" {
+		t.Errorf("unexpected header %v", records[0])
+	}
+
+	want := [][]string{
+		{".", "directory", "30", "4", "5", "", ""},
+		{"a,\"b\"\nc.txt", "file", "12", "3", "3", "ff", ""},
+		{"tab\there.go", "file", "8", "1", "2", "", ""},
+		{"logo.png", "non-text", "10", "0", "0", "", "non-text - content not included"},
+	}
+	for i, w := range want {
+		if strings.Join(records[i+1], "|") != strings.Join(w, "|") {
+			t.Errorf("row %d = %q, want %q", i+1, records[i+1], w)
+		}
+	}
+}
+
+func TestWriteTSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := csvTestResult().WriteTSV(&buf, IngestionOptions{}); err != nil {
+		t.Fatalf("WriteTSV returned error: %v", err)
+	}
+	r := csv.NewReader(&buf)
+	r.Comma = '\t'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid TSV: %v", err)
+	}
+	if records[3][0] != "tab\there.go" || records[3][3] != "1" {
+		t.Errorf("tab in path was not escaped: %q", records[3])
+	}
+}
+
+func TestWriteCSV_NeutralizesFormulas(t *testing.T) {
+	r := &Result{RootNode: &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "=HYPERLINK(\"x\").txt", Path: "=HYPERLINK(\"x\").txt", Type: NodeTypeFile},
+			{Name: "-rf.sh", Path: "-rf.sh", Type: NodeTypeFile},
+			{Name: "@home.md", Path: "@home.md", Type: NodeTypeFile},
+			{Name: "+1.txt", Path: "+1.txt", Type: NodeTypeFile},
+			{Name: "a=b.txt", Path: "a=b.txt", Type: NodeTypeFile},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf, IngestionOptions{}); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	want := []string{".", "'=HYPERLINK(\"x\").txt", "'-rf.sh", "'@home.md", "'+1.txt", "a=b.txt"}
+	for i, w := range want {
+		if records[i+1][0] != w {
+			t.Errorf("row %d path = %q, want %q", i+1, records[i+1][0], w)
+		}
+	}
+}
+
+func TestWriteCSV_DuplicateTokens(t *testing.T) {
+	r := &Result{RootNode: &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "a.txt", Path: "a.txt", Type: NodeTypeFile, Content: "12345678"},
+			{Name: "b.txt", Path: "b.txt", Type: NodeTypeFile, Content: "12345678", DuplicateOf: "a.txt"},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf, IngestionOptions{Dedupe: true}); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	for i, want := range []string{"2", "2", "0"} {
+		if records[i+1][4] != want {
+			t.Errorf("row %d tokens = %q, want %q", i+1, records[i+1][4], want)
+		}
+	}
+}
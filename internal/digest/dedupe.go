@@ -0,0 +1,56 @@
+package digest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+)
+
+// markDuplicates points every text file whose content equals that of an
+// earlier file in content order at that file, and removes the duplicates
+// from the token count. The content is compared after redaction and
+// transforms, as it would appear in the digest, and stays on the node.
+func (r *Result) markDuplicates(opts IngestionOptions) {
+	first := make(map[[sha256.Size]byte]*FileNode)
+	for _, node := range r.contentNodes(opts) {
+		if node.Type != NodeTypeFile || node.Content == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(node.Content))
+		if orig, ok := first[sum]; ok {
+			node.DuplicateOf = filepath.ToSlash(orig.Path)
+			r.TokenCount -= estimateTokens(node.Content)
+			continue
+		}
+		first[sum] = node
+	}
+}
+
+// dedupeTotal sums the files left out of a digest as duplicates.
+type dedupeTotal struct {
+	Files       int
+	TokensSaved int
+	BytesSaved  int64
+}
+
+func (r *Result) dedupeTotal() dedupeTotal {
+	var total dedupeTotal
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		if node.DuplicateOf != "" {
+			total.Files++
+			total.TokensSaved += estimateTokens(node.Content)
+			total.BytesSaved += int64(len(node.Content))
+		}
+		return true
+	})
+	return total
+}
+
+func (r *Result) dedupeSummaryRows() []summaryRow {
+	total := r.dedupeTotal()
+	if total.Files == 0 {
+		return nil
+	}
+	value := fmt.Sprintf("%d files, %d tokens saved (%s)", total.Files, total.TokensSaved, formatBytes(total.BytesSaved))
+	return []summaryRow{{"Duplicates", value}}
+}
@@ -0,0 +1,103 @@
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const dedupeTestLicense = "Permission is hereby granted, free of charge, to any person obtaining a copy.\n"
+
+func dedupeTestTree(t *testing.T) string {
+	return writeTestTree(t, map[string]string{
+		"a/LICENSE":            dedupeTestLicense,
+		"vendor/b/LICENSE":     dedupeTestLicense,
+		"vendor/c/LICENSE.txt": dedupeTestLicense,
+		"main.go":              "package main\n",
+	})
+}
+
+func TestDedupe(t *testing.T) {
+	opts := IngestionOptions{Source: dedupeTestTree(t), MaxFileSize: 1024, Dedupe: true}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+
+	if n := strings.Count(r.FileContents, dedupeTestLicense); n != 1 {
+		t.Errorf("license content appears %d times, want 1:\n%s", n, r.FileContents)
+	}
+	for _, want := range []string{
+		"File: vendor/b/LICENSE (identical to a/LICENSE)\n",
+		"File: vendor/c/LICENSE.txt (identical to a/LICENSE)\n",
+	} {
+		if !strings.Contains(r.FileContents, want) {
+			t.Errorf("expected %q in content:\n%s", want, r.FileContents)
+		}
+	}
+	saved := 2 * estimateTokens(dedupeTestLicense)
+	if want := estimateTokens(dedupeTestLicense) + estimateTokens("package main\n"); r.TokenCount != want {
+		t.Errorf("TokenCount = %d, want %d", r.TokenCount, want)
+	}
+	if want := fmt.Sprintf("Duplicates: 2 files, %d tokens saved", saved); !strings.Contains(r.Summary, want) {
+		t.Errorf("expected %q in summary:\n%s", want, r.Summary)
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	assertValidJSONDigest(t, r, opts)
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if d := out.Summary.Duplicates; d == nil || d.Files != 2 || d.TokensSaved != saved || d.BytesSaved != int64(2*len(dedupeTestLicense)) {
+		t.Errorf("summary duplicates = %+v", out.Summary.Duplicates)
+	}
+	for _, f := range out.Files {
+		dup := strings.HasPrefix(f.Path, "vendor/")
+		if dup && (f.DuplicateOf != "a/LICENSE" || f.Content != "") || !dup && f.DuplicateOf != "" {
+			t.Errorf("file %s: duplicate_of = %q, content = %q", f.Path, f.DuplicateOf, f.Content)
+		}
+	}
+}
+
+func TestDedupe_RestoresDuplicates(t *testing.T) {
+	opts := IngestionOptions{Source: dedupeTestTree(t), MaxFileSize: 1024, Dedupe: true}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+
+	for name, digest := range map[string][]byte{"text": []byte(r.TreeStructure + r.FileContents), "json": data} {
+		entries, err := ParseDigest(digest)
+		if err != nil {
+			t.Fatalf("%s: ParseDigest returned error: %v", name, err)
+		}
+		for _, e := range entries {
+			if strings.Contains(e.Path, "LICENSE") && (e.Content != dedupeTestLicense || e.Placeholder != "") {
+				t.Errorf("%s: entry %+v, want the license content", name, e)
+			}
+		}
+	}
+}
+
+func TestDedupe_OffByDefault(t *testing.T) {
+	opts := IngestionOptions{Source: dedupeTestTree(t), MaxFileSize: 1024}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+	if n := strings.Count(r.FileContents, dedupeTestLicense); n != 3 {
+		t.Errorf("license content appears %d times, want 3", n)
+	}
+}
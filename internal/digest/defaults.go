@@ -67,6 +67,40 @@ var DefaultExcludePatterns = []string{
 	"crash.dump",
 
 	// Gitingest / RepoLlama specific
-	"digest.txt",            // If the default output is called this
-	"pathdigest_digest.txt", // Tooling specific
+	"digest.txt",          // If the default output is called this
+	"pathdigest_digest.*", // Tooling specific, default output of every format
+}
+
+// DocPatterns rank documentation files for the docs-first order. Earlier
+// patterns rank higher.
+var DocPatterns = []string{
+	"README*",
+	"Readme*",
+	"readme*",
+	"ARCHITECTURE*",
+	"CONTRIBUTING*",
+	"CHANGELOG*",
+	"*.md",
+	"*.mdx",
+	"*.rst",
+	"*.adoc",
+	"docs/",
+	"doc/",
+}
+
+// EntrypointPatterns rank program entry points for the entrypoints-first
+// order. Earlier patterns rank higher.
+var EntrypointPatterns = []string{
+	"main.go",
+	"main.*",
+	"__main__.py",
+	"Main.java",
+	"Program.cs",
+	"index.*",
+	"app.*",
+	"server.*",
+	"cli.*",
+	"lib.rs",
+	"manage.py",
+	"cmd/",
 }
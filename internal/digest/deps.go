@@ -0,0 +1,75 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/deps"
+)
+
+// findDependencies parses the dependency manifests below root, skipping
+// excluded files and directories. Lockfiles are read even though they are
+// excluded by default.
+func findDependencies(root string, opts IngestionOptions) []deps.Manifest {
+	return deps.Find(root, func(rel string, isDir bool) bool {
+		return isPathMatchWithInfo(rel, isDir, opts.ExcludePatterns)
+	})
+}
+
+// formatDependencies renders the Dependencies section of the text digest:
+// each manifest followed by one indented line per direct dependency.
+func formatDependencies(manifests []deps.Manifest) string {
+	if len(manifests) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Dependencies:\n")
+	for _, m := range manifests {
+		source := m.Ecosystem
+		if m.Lockfile != "" {
+			source += ", " + m.Lockfile
+		}
+		sb.WriteString(fmt.Sprintf("%s (%s)\n", m.Path, source))
+		if m.Err != nil {
+			sb.WriteString(fmt.Sprintf("  error: %v\n", m.Err))
+		}
+		for _, d := range m.Dependencies {
+			sb.WriteString("  " + formatDependency(d) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// formatDependency returns "name version", with the declared constraint in
+// parentheses when it differs from the resolved version.
+func formatDependency(d deps.Dependency) string {
+	s := d.Name
+	var notes []string
+	switch {
+	case d.Version != "":
+		s += " " + d.Version
+		if d.Constraint != "" && d.Constraint != d.Version {
+			notes = append(notes, d.Constraint)
+		}
+	case d.Constraint != "":
+		s += " " + d.Constraint
+	}
+	if d.Dev {
+		notes = append(notes, "dev")
+	}
+	if len(notes) > 0 {
+		s += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return s
+}
+
+func (r *Result) dependencySummaryRows() []summaryRow {
+	if len(r.Dependencies) == 0 {
+		return nil
+	}
+	total := 0
+	for _, m := range r.Dependencies {
+		total += len(m.Dependencies)
+	}
+	return []summaryRow{{"Dependencies", fmt.Sprintf("%d in %d manifest(s)", total, len(r.Dependencies))}}
+}
@@ -0,0 +1,77 @@
+package digest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"go.mod":                          "module example.com/app\n\nrequire github.com/spf13/cobra v1.8.0\n",
+		"package.json":                    `{"dependencies": {"react": "^18.2.0"}, "devDependencies": {"vite": "^5.0.0"}}`,
+		"package-lock.json":               `{"lockfileVersion": 3, "packages": {"node_modules/react": {"version": "18.3.1"}}}`,
+		"node_modules/react/package.json": `{"dependencies": {"loose-envify": "^1.1.0"}}`,
+		"main.go":                         "package main\n",
+	})
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1 << 20, ExcludePatterns: DefaultExcludePatterns, Deps: true}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+
+	var sb strings.Builder
+	f, _ := LookupFormatter("text")
+	if err := f.Format(&sb, r, opts); err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	want := "Dependencies:\n" +
+		"go.mod (go)\n" +
+		"  github.com/spf13/cobra v1.8.0\n" +
+		"package.json (npm, package-lock.json)\n" +
+		"  react 18.3.1 (^18.2.0)\n" +
+		"  vite ^5.0.0 (dev)\n\n" + fileSeparator
+	if !strings.Contains(sb.String(), want) {
+		t.Errorf("expected the Dependencies section before the files:\n%s", sb.String())
+	}
+	if strings.Contains(sb.String(), "loose-envify") {
+		t.Error("dependencies of excluded node_modules were listed")
+	}
+	if !strings.Contains(r.Summary, "Dependencies: 3 in 2 manifest(s)") {
+		t.Errorf("summary does not count dependencies:\n%s", r.Summary)
+	}
+	entries, err := ParseDigest([]byte(sb.String()))
+	if err != nil || len(entries) != 3 {
+		t.Errorf("ParseDigest = %d entries, %v; want the 3 included files", len(entries), err)
+	}
+
+	assertValidJSONDigest(t, r, opts)
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.Dependencies) != 2 {
+		t.Fatalf("dependencies = %+v, want 2 manifests", out.Dependencies)
+	}
+	npm := out.Dependencies[1]
+	wantNPM := []JSONDependency{{Name: "react", Constraint: "^18.2.0", Version: "18.3.1"}, {Name: "vite", Constraint: "^5.0.0", Dev: true}}
+	if npm.Path != "package.json" || npm.Lockfile != "package-lock.json" || len(npm.Dependencies) != 2 ||
+		npm.Dependencies[0] != wantNPM[0] || npm.Dependencies[1] != wantNPM[1] {
+		t.Errorf("package.json manifest = %+v", npm)
+	}
+}
+
+func TestDependencies_OffByDefault(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{"go.mod": "module m\n\nrequire a.b/c v1.0.0\n"})
+	r, err := ProcessSource(IngestionOptions{Source: dir, MaxFileSize: 1024})
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	if r.Dependencies != nil {
+		t.Errorf("Dependencies = %+v without Deps", r.Dependencies)
+	}
+}
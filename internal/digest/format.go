@@ -10,53 +10,148 @@ const (
 	fileSeparator = "================================================\n"
 )
 
+// summaryRow is a single "Label: Value" entry of the digest summary.
+type summaryRow struct {
+	Label string
+	Value string
+}
+
 func (r *Result) FormatOutput(opts IngestionOptions) {
-	var sbSummary, sbTree, sbContent strings.Builder
+	var sbTree, sbContent strings.Builder
 
-	sbSummary.WriteString(createSummaryPrefix(opts, r.RootNode.Type == NodeTypeFile))
-	if r.RootNode.Type == NodeTypeDir {
-		sbSummary.WriteString(fmt.Sprintf("Files analyzed: %d\n", r.TotalFiles))
-		sbSummary.WriteString(fmt.Sprintf("Total size: %s\n", formatBytes(r.TotalSize)))
-	} else if r.RootNode.Type == NodeTypeFile {
-		sbSummary.WriteString(fmt.Sprintf("File: %s\n", r.RootNode.Name))
-		sbSummary.WriteString(fmt.Sprintf("Size: %s\n", formatBytes(r.RootNode.Size)))
-		if r.RootNode.Type == NodeTypeFile {
-			sbSummary.WriteString(fmt.Sprintf("Lines: %d\n", strings.Count(r.RootNode.Content, "\n")+1))
-		}
-	}
-	r.Summary = sbSummary.String()
+	r.Summary = formatSummaryRows(r.summaryRows(opts))
 
 	if r.RootNode.Type == NodeTypeDir {
 		sbTree.WriteString("Directory structure:\n")
-		buildTreeStructure(&sbTree, r.RootNode, "", true)
-	} else if r.RootNode.Type == NodeTypeFile {
+	} else if r.isSingleFile() {
 		sbTree.WriteString("File processed:\n")
-		sbTree.WriteString(fmt.Sprintf("└── %s\n", r.RootNode.Name))
 	}
+	r.writeTree(&sbTree)
 	r.TreeStructure = sbTree.String()
+	r.DependencyList = formatDependencies(r.Dependencies)
 
-	gatherFileContents(&sbContent, r.RootNode)
+	for _, node := range r.contentNodes(opts) {
+		writeFileContent(&sbContent, node, opts)
+	}
 	r.FileContents = sbContent.String()
 }
 
-func createSummaryPrefix(opts IngestionOptions, isSingleFile bool) string {
-	var parts []string
+func (r *Result) isSingleFile() bool {
+	return r.RootNode.Type == NodeTypeFile || r.RootNode.Type == NodeTypeTruncated
+}
+
+// writeTree writes the tree diagram without a heading.
+func (r *Result) writeTree(sb *strings.Builder) {
+	if r.RootNode.Type == NodeTypeDir {
+		buildTreeStructure(sb, r.RootNode, "", true)
+	} else if r.isSingleFile() {
+		sb.WriteString(fmt.Sprintf("└── %s\n", r.RootNode.Name))
+	}
+}
+
+func (r *Result) summaryRows(opts IngestionOptions) []summaryRow {
+	rows := summaryPrefixRows(opts, r.isSingleFile())
+	if r.RootNode.Type == NodeTypeDir {
+		rows = append(rows,
+			summaryRow{"Files analyzed", fmt.Sprintf("%d", r.TotalFiles)},
+			summaryRow{"Total size", formatBytes(r.TotalSize)},
+		)
+		if opts.LineNumbers {
+			rows = append(rows, summaryRow{"Total lines", fmt.Sprintf("%d", r.totalLines(opts))})
+		}
+	} else if r.isSingleFile() {
+		rows = append(rows,
+			summaryRow{"File", r.RootNode.Name},
+			summaryRow{"Size", formatBytes(r.RootNode.Size)},
+			summaryRow{"Lines", fmt.Sprintf("%d", fileLineCount(r.RootNode))},
+		)
+	}
+	rows = append(rows, r.transformSummaryRows()...)
+	rows = append(rows, r.licenseSummaryRows()...)
+	rows = append(rows, r.secretsSummaryRows(opts)...)
+	rows = append(rows, r.piiSummaryRows()...)
+	rows = append(rows, r.dedupeSummaryRows()...)
+	rows = append(rows, r.dependencySummaryRows()...)
+	rows = append(rows, summaryRow{"Estimated tokens", fmt.Sprintf("%d", r.TokenCount)})
+	if opts.Model != nil {
+		rows = append(rows, EstimateModelFit(*opts.Model, r.fitTokens()).summaryRows()...)
+	}
+	return rows
+}
+
+func summaryPrefixRows(opts IngestionOptions, isSingleFile bool) []summaryRow {
+	var rows []summaryRow
 	if isSingleFile {
-		parts = append(parts, fmt.Sprintf("Source File: %s", opts.Source))
+		rows = append(rows, summaryRow{"Source File", opts.Source})
 	} else {
-		parts = append(parts, fmt.Sprintf("Source Directory: %s", opts.Source))
+		rows = append(rows, summaryRow{"Source Directory", opts.Source})
 	}
 	if len(opts.IncludePatterns) > 0 {
-		parts = append(parts, fmt.Sprintf("Include Patterns: %s", strings.Join(opts.IncludePatterns, ", ")))
+		rows = append(rows, summaryRow{"Include Patterns", strings.Join(opts.IncludePatterns, ", ")})
 	}
 	if len(opts.ExcludePatterns) > 0 {
-		parts = append(parts, fmt.Sprintf("Exclude Patterns: %s", strings.Join(opts.ExcludePatterns, ", ")))
+		rows = append(rows, summaryRow{"Exclude Patterns", strings.Join(opts.ExcludePatterns, ", ")})
 	}
 	if opts.MaxFileSize > 0 {
-		parts = append(parts, fmt.Sprintf("Max File Size: %s", formatBytes(opts.MaxFileSize)))
+		rows = append(rows, summaryRow{"Max File Size", formatBytes(opts.MaxFileSize)})
 	}
+	if opts.Order != "" && opts.Order != OrderAlphabetical {
+		rows = append(rows, summaryRow{"Order", opts.Order})
+	}
+	if len(opts.PriorityPatterns) > 0 {
+		rows = append(rows, summaryRow{"Priority Patterns", strings.Join(opts.PriorityPatterns, ", ")})
+	}
+	if opts.LineNumbers {
+		rows = append(rows, summaryRow{"Line Numbers", "enabled"})
+	}
+	if opts.StripComments {
+		value := "enabled"
+		if opts.KeepDocComments {
+			value = "enabled, keeping doc comments"
+		}
+		rows = append(rows, summaryRow{"Strip Comments", value})
+	}
+	if opts.StripLicenseHeaders {
+		value := "enabled"
+		if opts.LicenseTemplate != "" {
+			value = "enabled, with template"
+		}
+		rows = append(rows, summaryRow{"Strip License Headers", value})
+	}
+	if opts.Compact {
+		value := "enabled"
+		if opts.CompactTabWidth > 0 {
+			value = fmt.Sprintf("enabled, tabs of %d columns", opts.CompactTabWidth)
+		}
+		rows = append(rows, summaryRow{"Compact", value})
+	}
+	if len(opts.Outline) > 0 {
+		rows = append(rows, summaryRow{"Outline", strings.Join(opts.Outline, ", ")})
+	}
+	if len(opts.OutlineKeep) > 0 {
+		rows = append(rows, summaryRow{"Outline Keep Patterns", strings.Join(opts.OutlineKeep, ", ")})
+	}
+	if opts.Secrets != "" && opts.Secrets != SecretsOff {
+		rows = append(rows, summaryRow{"Secret Scanning", opts.Secrets})
+	}
+	if opts.RedactPII {
+		rows = append(rows, summaryRow{"PII Redaction", "enabled"})
+	}
+	if opts.Dedupe {
+		rows = append(rows, summaryRow{"Dedupe", "enabled"})
+	}
+	if opts.TruncateLarge.Enabled() {
+		rows = append(rows, summaryRow{"Truncate Large Files", opts.TruncateLarge.String()})
+	}
+	return rows
+}
 
-	return strings.Join(parts, "\n") + "\n"
+func formatSummaryRows(rows []summaryRow) string {
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", row.Label, row.Value))
+	}
+	return sb.String()
 }
 
 func buildTreeStructure(sb *strings.Builder, node *FileNode, prefix string, isLast bool) {
@@ -75,6 +170,8 @@ func buildTreeStructure(sb *strings.Builder, node *FileNode, prefix string, isLa
 		displayName += " (non-text)"
 	case NodeTypeTooLarge:
 		displayName += fmt.Sprintf(" (too large: %s)", formatBytes(node.Size))
+	case NodeTypeTruncated:
+		displayName += fmt.Sprintf(" (truncated: %s)", formatBytes(node.Size))
 	case NodeTypeExcluded:
 		displayName += " (excluded)"
 	}
@@ -94,13 +191,29 @@ func buildTreeStructure(sb *strings.Builder, node *FileNode, prefix string, isLa
 	}
 }
 
-func gatherFileContents(sb *strings.Builder, node *FileNode) {
-	if node.Type == NodeTypeFile && node.Content != "" {
+func writeFileContent(sb *strings.Builder, node *FileNode, opts IngestionOptions) {
+	if node.DuplicateOf != "" {
+		sb.WriteString(fileSeparator)
+		sb.WriteString(fmt.Sprintf("File: %s (identical to %s)\n", filepath.ToSlash(node.Path), node.DuplicateOf))
+		sb.WriteString(fileSeparator)
+		sb.WriteString("\n\n")
+	} else if node.Type == NodeTypeFile && node.Content != "" {
 		sb.WriteString(fileSeparator)
 		sb.WriteString(fmt.Sprintf("File: %s\n", filepath.ToSlash(node.Path)))
 		sb.WriteString(fileSeparator)
-		sb.WriteString(node.Content)
-		if !strings.HasSuffix(node.Content, "\n") {
+		content := displayContent(node, opts)
+		sb.WriteString(content)
+		if !strings.HasSuffix(content, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	} else if node.Type == NodeTypeTruncated {
+		sb.WriteString(fileSeparator)
+		sb.WriteString(fmt.Sprintf("File: %s (truncated)\n", filepath.ToSlash(node.Path)))
+		sb.WriteString(fileSeparator)
+		content := displayContent(node, opts)
+		sb.WriteString(content)
+		if !strings.HasSuffix(content, "\n") {
 			sb.WriteString("\n")
 		}
 		sb.WriteString("\n")
@@ -108,13 +221,10 @@ func gatherFileContents(sb *strings.Builder, node *FileNode) {
 		sb.WriteString(fileSeparator)
 		sb.WriteString(fmt.Sprintf("File: %s (%s - content not included)\n", filepath.ToSlash(node.Path), node.Type))
 		sb.WriteString(fileSeparator)
-		sb.WriteString("\n\n")
-	}
-
-	if node.Type == NodeTypeDir {
-		for _, child := range node.Children {
-			gatherFileContents(sb, child)
+		if node.Description != "" {
+			sb.WriteString(node.Description + "\n")
 		}
+		sb.WriteString("\n\n")
 	}
 }
 
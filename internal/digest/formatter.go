@@ -0,0 +1,156 @@
+package digest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Formatter renders an ingestion Result in one output format.
+type Formatter interface {
+	Name() string
+	Format(w io.Writer, r *Result, opts IngestionOptions) error
+}
+
+var formatters = make(map[string]Formatter)
+
+// RegisterFormatter makes a formatter available by name. It panics if a
+// formatter with the same name is already registered.
+func RegisterFormatter(f Formatter) {
+	name := f.Name()
+	if _, dup := formatters[name]; dup {
+		panic(fmt.Sprintf("digest: formatter %q registered twice", name))
+	}
+	formatters[name] = f
+}
+
+// LookupFormatter returns the formatter registered under name.
+func LookupFormatter(name string) (Formatter, bool) {
+	f, ok := formatters[name]
+	return f, ok
+}
+
+// FormatterNames returns the names of all registered formatters, sorted.
+func FormatterNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// outputExtensions are the extensions of the default output file per
+// format.
+var outputExtensions = map[string]string{
+	"text": ".txt", "json": ".json", "ndjson": ".ndjson", "yaml": ".yaml", "toml": ".toml",
+	"markdown": ".md", "xml": ".xml", "html": ".html", "csv": ".csv", "tsv": ".tsv",
+	"tar": ".tar", "zip": ".zip",
+}
+
+// DefaultOutputFile returns the output file written when none is given:
+// pathdigest_digest with the extension of format, or .txt for a format
+// without one, such as a template.
+func DefaultOutputFile(format string) string {
+	ext, ok := outputExtensions[format]
+	if !ok {
+		ext = ".txt"
+	}
+	return "pathdigest_digest" + ext
+}
+
+// promptFormats are the formats meant to be pasted into a prompt. Archives
+// and manifests are not, so their model fit is estimated from file content.
+var promptFormats = map[string]bool{
+	"text": true, "markdown": true, "xml": true, "json": true, "yaml": true, "toml": true,
+}
+
+// Render writes r to w with f. When opts.Model is set and f is a prompt
+// format, the output is measured as it is written, so that the model fit in
+// the summary covers the tree, the summary and the markup of the format
+// rather than file content only. Fits embedded in the output itself are
+// computed before it is written and count file content.
+func Render(f Formatter, w io.Writer, r *Result, opts IngestionOptions) error {
+	r.OutputTokens = 0
+	if opts.Model == nil || !promptFormats[f.Name()] {
+		return f.Format(w, r, opts)
+	}
+	var c tokenCounter
+	if err := f.Format(io.MultiWriter(w, &c), r, opts); err != nil {
+		return err
+	}
+	r.OutputTokens = c.tokens()
+	if r.Summary != "" {
+		r.Summary = formatSummaryRows(r.summaryRows(opts))
+	}
+	return nil
+}
+
+// FormatModelFit renders the model fit rows of the summary, or "" when
+// opts.Model is not set.
+func FormatModelFit(r *Result, opts IngestionOptions) string {
+	if opts.Model == nil {
+		return ""
+	}
+	return formatSummaryRows(EstimateModelFit(*opts.Model, r.fitTokens()).summaryRows())
+}
+
+type formatterFunc struct {
+	name   string
+	format func(w io.Writer, r *Result, opts IngestionOptions) error
+}
+
+func (f formatterFunc) Name() string { return f.name }
+
+func (f formatterFunc) Format(w io.Writer, r *Result, opts IngestionOptions) error {
+	return f.format(w, r, opts)
+}
+
+// bytesFormatter adapts a Result method that returns the whole output.
+func bytesFormatter(name string, format func(r *Result, opts IngestionOptions) ([]byte, error)) Formatter {
+	return formatterFunc{name: name, format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		data, err := format(r, opts)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}}
+}
+
+func init() {
+	RegisterFormatter(formatterFunc{name: "text", format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		r.FormatOutput(opts)
+		deps := r.DependencyList
+		if deps != "" {
+			deps += "\n"
+		}
+		_, err := io.WriteString(w, r.TreeStructure+"\n"+deps+r.FileContents)
+		return err
+	}})
+	RegisterFormatter(bytesFormatter("json", (*Result).FormatJSON))
+	RegisterFormatter(formatterFunc{name: "ndjson", format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		return r.WriteNDJSON(w, opts)
+	}})
+	RegisterFormatter(bytesFormatter("yaml", (*Result).FormatYAML))
+	RegisterFormatter(bytesFormatter("toml", (*Result).FormatTOML))
+	RegisterFormatter(bytesFormatter("markdown", func(r *Result, opts IngestionOptions) ([]byte, error) {
+		return r.FormatMarkdown(opts), nil
+	}))
+	RegisterFormatter(bytesFormatter("xml", func(r *Result, opts IngestionOptions) ([]byte, error) {
+		return r.FormatXML(opts), nil
+	}))
+	RegisterFormatter(bytesFormatter("html", (*Result).FormatHTML))
+	RegisterFormatter(formatterFunc{name: "csv", format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		return r.WriteCSV(w, opts)
+	}})
+	RegisterFormatter(formatterFunc{name: "tsv", format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		return r.WriteTSV(w, opts)
+	}})
+	RegisterFormatter(formatterFunc{name: "tar", format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		return r.WriteTar(w, opts)
+	}})
+	RegisterFormatter(formatterFunc{name: "zip", format: func(w io.Writer, r *Result, opts IngestionOptions) error {
+		return r.WriteZip(w, opts)
+	}})
+}
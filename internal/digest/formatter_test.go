@@ -0,0 +1,98 @@
+package digest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinFormattersRegistered(t *testing.T) {
+	r := restoreTestResult()
+	for _, name := range []string{"text", "json", "ndjson", "yaml", "toml", "markdown", "xml", "html"} {
+		t.Run(name, func(t *testing.T) {
+			f, ok := LookupFormatter(name)
+			if !ok {
+				t.Fatalf("formatter %q is not registered", name)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, r, IngestionOptions{Source: "."}); err != nil {
+				t.Fatalf("Format returned error: %v", err)
+			}
+			if !strings.Contains(buf.String(), "main.go") {
+				t.Errorf("%s output does not mention main.go", name)
+			}
+		})
+	}
+}
+
+func TestRegisterFormatter_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate formatter did not panic")
+		}
+	}()
+	RegisterFormatter(formatterFunc{name: "json", format: func(io.Writer, *Result, IngestionOptions) error { return nil }})
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	tmpl := `{{range .Files}}{{if .Content}}[{{slash .Path}} {{language .Path}} {{tokens .Content}}]
+{{fence .Content}}
+{{indent 2 .Content}}{{end}}{{end}}`
+	if err := os.WriteFile(path, []byte(tmpl), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	f, err := NewTemplateFormatter(path)
+	if err != nil {
+		t.Fatalf("NewTemplateFormatter returned error: %v", err)
+	}
+
+	root := &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "main.go", Path: "main.go", Type: NodeTypeFile, Content: "package main\n\nfunc main() {}\n"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, &Result{RootNode: root}, IngestionOptions{}); err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+
+	want := "[main.go go 8]\n```\n  package main\n\n  func main() {}\n"
+	if buf.String() != want {
+		t.Errorf("template output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewTemplateFormatter_ParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	os.WriteFile(path, []byte("{{range}}"), 0644)
+	if _, err := NewTemplateFormatter(path); err == nil {
+		t.Error("NewTemplateFormatter accepted an invalid template")
+	}
+}
+
+func TestDefaultOutputFile(t *testing.T) {
+	tests := map[string]string{
+		"text":     "pathdigest_digest.txt",
+		"markdown": "pathdigest_digest.md",
+		"zip":      "pathdigest_digest.zip",
+		"tar":      "pathdigest_digest.tar",
+		"ndjson":   "pathdigest_digest.ndjson",
+		"template": "pathdigest_digest.txt",
+	}
+	for format, want := range tests {
+		if got := DefaultOutputFile(format); got != want {
+			t.Errorf("DefaultOutputFile(%q) = %q, want %q", format, got, want)
+		}
+	}
+	for _, name := range FormatterNames() {
+		if _, ok := outputExtensions[name]; !ok {
+			t.Errorf("formatter %q has no default output extension", name)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+)
+
+type htmlReport struct {
+	Title   string
+	Summary []summaryRow
+	Tree    *htmlTreeNode
+	Files   []htmlFile
+}
+
+type htmlTreeNode struct {
+	Name     string
+	Label    string
+	Anchor   string
+	IsDir    bool
+	Children []*htmlTreeNode
+}
+
+type htmlFile struct {
+	Anchor   string
+	Path     string
+	Language string
+	Note     string
+	Content  string
+}
+
+// FormatHTML renders the digest as a single self-contained HTML page with a
+// collapsible tree, a summary panel and client-side search. All styles and
+// scripts are inlined so the file works offline.
+func (r *Result) FormatHTML(opts IngestionOptions) ([]byte, error) {
+	report := htmlReport{
+		Title:   r.RootNode.Name,
+		Summary: r.summaryRows(opts),
+	}
+
+	anchors := make(map[*FileNode]string)
+	for i, node := range r.contentNodes(opts) {
+		anchor := fmt.Sprintf("file-%d", i+1)
+		anchors[node] = anchor
+
+		f := htmlFile{
+			Anchor:   anchor,
+			Path:     filepath.ToSlash(node.Path),
+			Language: langutil.Detect(node.Path),
+			Content:  node.Content,
+		}
+		switch node.Type {
+		case NodeTypeFile:
+			if node.DuplicateOf != "" {
+				f.Note = "identical to " + node.DuplicateOf
+				f.Content = ""
+			} else if node.Content == "" {
+				f.Note = "empty"
+			}
+		case NodeTypeTruncated:
+			f.Note = "truncated"
+		case NodeTypeNotText, NodeTypeTooLarge:
+			f.Note = fmt.Sprintf("%s - content not included", node.Type)
+			if node.Description != "" {
+				f.Note += ": " + node.Description
+			}
+		}
+		report.Files = append(report.Files, f)
+	}
+	report.Tree = buildHTMLTree(r.RootNode, anchors)
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, report); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func buildHTMLTree(node *FileNode, anchors map[*FileNode]string) *htmlTreeNode {
+	if node == nil {
+		return nil
+	}
+	hn := &htmlTreeNode{
+		Name:   node.Name,
+		Anchor: anchors[node],
+		IsDir:  node.Type == NodeTypeDir,
+	}
+	switch node.Type {
+	case NodeTypeSymlink:
+		hn.Label = "symlink"
+	case NodeTypeNotText:
+		hn.Label = "non-text"
+	case NodeTypeTooLarge:
+		hn.Label = "too large: " + formatBytes(node.Size)
+	case NodeTypeTruncated:
+		hn.Label = "truncated: " + formatBytes(node.Size)
+	case NodeTypeExcluded:
+		hn.Label = "excluded"
+	}
+	for _, child := range node.Children {
+		hn.Children = append(hn.Children, buildHTMLTree(child, anchors))
+	}
+	return hn
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - pathdigest</title>
+<style>
+:root { --fg: #1f2328; --muted: #656d76; --border: #d0d7de; --bg-alt: #f6f8fa; --accent: #0969da; --mark: #fff8c5; }
+* { box-sizing: border-box; }
+body { margin: 0; font: 14px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: var(--fg); }
+header { position: sticky; top: 0; z-index: 1; display: flex; gap: 12px; align-items: center; padding: 10px 16px; background: #fff; border-bottom: 1px solid var(--border); }
+header h1 { margin: 0; font-size: 16px; flex: 1; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+header input { width: 320px; max-width: 50vw; padding: 6px 10px; border: 1px solid var(--border); border-radius: 6px; font: inherit; }
+header .count { color: var(--muted); white-space: nowrap; }
+button { padding: 4px 10px; border: 1px solid var(--border); border-radius: 6px; background: var(--bg-alt); font: inherit; cursor: pointer; }
+.layout { display: grid; grid-template-columns: minmax(220px, 320px) 1fr; }
+nav { position: sticky; top: 53px; height: calc(100vh - 53px); overflow: auto; padding: 12px 16px; border-right: 1px solid var(--border); background: var(--bg-alt); }
+nav ul { list-style: none; margin: 0; padding-left: 14px; }
+nav > ul { padding-left: 0; }
+nav summary { cursor: pointer; font-weight: 600; }
+nav a { color: var(--accent); text-decoration: none; }
+nav a:hover { text-decoration: underline; }
+nav .label, .note { color: var(--muted); font-size: 12px; }
+nav .controls { display: flex; gap: 6px; margin-bottom: 8px; }
+main { padding: 16px; min-width: 0; }
+table.summary { border-collapse: collapse; margin-bottom: 24px; }
+table.summary th, table.summary td { text-align: left; vertical-align: top; padding: 4px 12px 4px 0; border-bottom: 1px solid var(--border); }
+table.summary td { word-break: break-word; }
+section.file { margin-bottom: 24px; border: 1px solid var(--border); border-radius: 6px; overflow: hidden; }
+section.file h2 { margin: 0; padding: 8px 12px; font-size: 14px; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; background: var(--bg-alt); border-bottom: 1px solid var(--border); }
+section.file pre { margin: 0; padding: 12px; overflow: auto; font: 12px/1.45 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; tab-size: 4; }
+mark { background: var(--mark); }
+[hidden] { display: none !important; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<input id="search" type="search" placeholder="Search paths and content..." autocomplete="off">
+<span class="count" id="count">{{len .Files}} files</span>
+</header>
+<div class="layout">
+<nav>
+<div class="controls"><button type="button" id="expand">Expand all</button><button type="button" id="collapse">Collapse all</button></div>
+{{with .Tree}}<ul>{{template "node" .}}</ul>{{end}}
+</nav>
+<main>
+<table class="summary">
+{{range .Summary}}<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{range .Files}}<section class="file" id="{{.Anchor}}" data-path="{{.Path}}">
+<h2>{{.Path}}{{if .Language}} <span class="note">{{.Language}}</span>{{end}}{{if .Note}} <span class="note">({{.Note}})</span>{{end}}</h2>
+{{if .Content}}<pre><code>{{.Content}}</code></pre>{{end}}
+</section>
+{{end}}</main>
+</div>
+<script>
+(function () {
+  var input = document.getElementById("search");
+  var count = document.getElementById("count");
+  var sections = Array.prototype.slice.call(document.querySelectorAll("section.file"));
+  var treeLinks = Array.prototype.slice.call(document.querySelectorAll("nav a[href^='#file-']"));
+
+  function filter() {
+    var term = input.value.trim().toLowerCase();
+    var shown = 0;
+    var visible = {};
+    sections.forEach(function (section) {
+      var code = section.querySelector("code");
+      var hit = !term ||
+        section.getAttribute("data-path").toLowerCase().indexOf(term) !== -1 ||
+        (code !== null && code.textContent.toLowerCase().indexOf(term) !== -1);
+      section.hidden = !hit;
+      if (hit) {
+        shown++;
+        visible[section.id] = true;
+      }
+    });
+    treeLinks.forEach(function (link) {
+      link.parentNode.style.opacity = visible[link.getAttribute("href").slice(1)] ? "" : "0.4";
+    });
+    count.textContent = term ? shown + " of " + sections.length + " files" : sections.length + " files";
+  }
+
+  function setOpen(open) {
+    document.querySelectorAll("nav details").forEach(function (d) { d.open = open; });
+  }
+
+  input.addEventListener("input", filter);
+  document.getElementById("expand").addEventListener("click", function () { setOpen(true); });
+  document.getElementById("collapse").addEventListener("click", function () { setOpen(false); });
+})();
+</script>
+</body>
+</html>
+{{define "node"}}<li>{{if .IsDir}}<details open><summary>{{.Name}}/</summary>{{if .Children}}<ul>{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}</details>{{else}}{{if .Anchor}}<a href="#{{.Anchor}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}{{if .Label}} <span class="label">({{.Label}})</span>{{end}}{{end}}</li>{{end}}
+`))
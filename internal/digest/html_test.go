@@ -0,0 +1,53 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatHTML(t *testing.T) {
+	root := &FileNode{
+		Name: "project",
+		Path: ".",
+		Type: NodeTypeDir,
+		Children: []*FileNode{
+			{
+				Name: "web", Path: "web", Type: NodeTypeDir, Depth: 1,
+				Children: []*FileNode{
+					{Name: "index.html", Path: "web/index.html", Type: NodeTypeFile, Size: 40, Depth: 2, Content: "<script>alert('x')</script>\n"},
+				},
+			},
+			{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 500, Depth: 1},
+		},
+	}
+	r := &Result{RootNode: root, TotalFiles: 2, TotalSize: 540}
+
+	data, err := r.FormatHTML(IngestionOptions{Source: "./project"})
+	if err != nil {
+		t.Fatalf("FormatHTML returned error: %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		`<summary>project/</summary>`,
+		`<a href="#file-1">index.html</a>`,
+		`<section class="file" id="file-1" data-path="web/index.html">`,
+		`&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;`,
+		`(non-text - content not included)`,
+		`<th>Source Directory</th><td>./project</td>`,
+		`id="search"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML output missing %q", want)
+		}
+	}
+
+	if strings.Contains(out, "<script>alert") {
+		t.Error("file content was not escaped")
+	}
+	for _, external := range []string{`src="http`, `href="http`, `@import`, `url(`} {
+		if strings.Contains(out, external) {
+			t.Errorf("HTML output references an external resource: %q", external)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package digest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
 	"os"
@@ -8,8 +10,10 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/ga1az/pathdigest/internal/binmeta"
 	"github.com/ga1az/pathdigest/internal/fsutil"
 	"github.com/ga1az/pathdigest/internal/gitutil"
+	"github.com/ga1az/pathdigest/internal/redact"
 )
 
 const (
@@ -17,6 +21,9 @@ const (
 )
 
 func ProcessSource(opts IngestionOptions) (*Result, error) {
+	if err := CheckLineNumbers(opts); err != nil {
+		return nil, err
+	}
 	if gitutil.IsLikelyGitURL(opts.Source) {
 		return processGitURL(opts)
 	}
@@ -37,11 +44,20 @@ func processLocalPath(opts IngestionOptions) (*Result, error) {
 		return nil, fmt.Errorf("failed to stat source path %s: %w", absSourcePath, err)
 	}
 
+	if opts.RedactPII && opts.piiRedactor == nil {
+		rules := opts.PIIRules
+		if rules == nil {
+			rules = redact.PIIRules
+		}
+		opts.piiRedactor = redact.NewPIIRedactor(rules)
+	}
+
 	rootNode := &FileNode{
 		Name:     filepath.Base(absSourcePath),
 		Path:     ".", // For the root node, the relative path to itself is "."
 		FullPath: absSourcePath,
 		Mode:     info.Mode(),
+		ModTime:  info.ModTime(),
 		Depth:    0,
 	}
 
@@ -83,25 +99,10 @@ func processLocalPath(opts IngestionOptions) (*Result, error) {
 
 		if !finalDecisionToProcess {
 			rootNode.Type = NodeTypeExcluded
-		} else if opts.MaxFileSize > 0 && rootNode.Size > opts.MaxFileSize {
-			rootNode.Type = NodeTypeTooLarge
-			totalFilesIngested = 1
-			totalSizeIngested = rootNode.Size
 		} else {
-			isText, errText := fsutil.IsTextFile(absSourcePath)
-			if errText != nil {
-				rootNode.Error = fmt.Errorf("error checking if file is text: %w", errText)
-				rootNode.Type = NodeTypeNotText
-			} else if !isText {
-				rootNode.Type = NodeTypeNotText
-			} else {
-				// TODO: Handle .ipynb (currently read as plain text)
-				content, errRead := fsutil.ReadFileContent(absSourcePath)
-				if errRead != nil {
-					rootNode.Error = fmt.Errorf("error reading file content: %w", errRead)
-				} else {
-					rootNode.Content = content
-				}
+			readFileNode(rootNode, opts)
+			if opts.onFile != nil {
+				opts.onFile(rootNode)
 			}
 			totalFilesIngested = 1
 			totalSizeIngested = rootNode.Size
@@ -112,6 +113,31 @@ func processLocalPath(opts IngestionOptions) (*Result, error) {
 		RootNode:   rootNode,
 		TotalFiles: totalFilesIngested,
 		TotalSize:  totalSizeIngested,
+		TokenCount: countTokens(rootNode),
+	}
+
+	if opts.Secrets == SecretsFail {
+		if findings := result.SecretFindings(); len(findings) > 0 {
+			return nil, &SecretsError{Findings: findings}
+		}
+	}
+
+	if opts.Order == OrderChurn && info.IsDir() {
+		churn, errChurn := gitutil.FileChurn(absSourcePath)
+		if errChurn != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not compute git churn for %s: %v; keeping the default order\n", absSourcePath, errChurn)
+		} else {
+			result.Churn = churn
+		}
+	}
+
+	if opts.Deps && info.IsDir() {
+		result.Dependencies = findDependencies(absSourcePath, opts)
+	}
+
+	// Duplicates are marked in content order, which may depend on churn.
+	if opts.Dedupe {
+		result.markDuplicates(opts)
 	}
 
 	return result, nil
@@ -149,7 +175,7 @@ func processGitURL(opts IngestionOptions) (*Result, error) {
 	}()
 
 	fmt.Fprintf(os.Stderr, "Cloning %s (branch: %s, commit: %s, subPath: %s) into %s...\n", gitParts.RepoURL, gitParts.Branch, gitParts.Commit, gitParts.SubPath, tempCloneDir)
-	clonedRepoPath, err := gitutil.CloneRepo(gitParts.RepoURL, tempCloneDir, gitParts.Branch, gitParts.Commit, gitParts.SubPath, gitParts.Type == "blob")
+	clonedRepoPath, err := gitutil.CloneRepo(gitParts.RepoURL, tempCloneDir, gitParts.Branch, gitParts.Commit, gitParts.SubPath, gitParts.Type == "blob", opts.Order == OrderChurn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
@@ -198,6 +224,14 @@ func processDirectory(currentDirNode *FileNode, basePath string, opts IngestionO
 	}
 
 	currentDirNode.Children = make([]*FileNode, 0, len(entries))
+	// Walk in the order sortNodes gives the tree, so that files are read in
+	// the default content order.
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return strings.ToLower(entries[i].Name()) < strings.ToLower(entries[j].Name())
+	})
 
 	for _, entry := range entries {
 		entryPath := filepath.Join(currentDirNode.FullPath, entry.Name())
@@ -257,7 +291,7 @@ func processDirectory(currentDirNode *FileNode, basePath string, opts IngestionO
 
 		childNode := &FileNode{
 			Name: entry.Name(), Path: relPath, FullPath: entryPath,
-			Size: info.Size(), Mode: info.Mode(), Depth: currentDepth + 1,
+			Size: info.Size(), Mode: info.Mode(), ModTime: info.ModTime(), Depth: currentDepth + 1,
 		}
 
 		if entry.IsDir() {
@@ -269,25 +303,9 @@ func processDirectory(currentDirNode *FileNode, basePath string, opts IngestionO
 			currentDirNode.Size += childNode.Size
 		} else if info.Mode().IsRegular() {
 			childNode.Type = NodeTypeFile
-
-			if opts.MaxFileSize > 0 && childNode.Size > opts.MaxFileSize {
-				childNode.Type = NodeTypeTooLarge
-			} else {
-				isText, errText := fsutil.IsTextFile(entryPath)
-				if errText != nil {
-					childNode.Error = fmt.Errorf("error checking if file is text: %w", errText)
-					childNode.Type = NodeTypeNotText
-				} else if !isText {
-					childNode.Type = NodeTypeNotText
-				} else {
-					// TODO: Handle .ipynb
-					content, errRead := fsutil.ReadFileContent(entryPath)
-					if errRead != nil {
-						childNode.Error = fmt.Errorf("error reading file content: %w", errRead)
-					} else {
-						childNode.Content = content
-					}
-				}
+			readFileNode(childNode, opts)
+			if opts.onFile != nil {
+				opts.onFile(childNode)
 			}
 			*totalFiles++
 			*totalSize += childNode.Size
@@ -305,6 +323,69 @@ func processDirectory(currentDirNode *FileNode, basePath string, opts IngestionO
 	return nil
 }
 
+// readFileNode classifies a regular file node and loads its content when it
+// is a text file within the size limit.
+func readFileNode(node *FileNode, opts IngestionOptions) {
+	readFileContent(node, opts)
+	scanSecrets(node, opts)
+	redactPII(node, opts)
+	if node.Type == NodeTypeFile && node.Content != "" {
+		applyContentTransforms(node, opts)
+	}
+	hashFileNode(node)
+}
+
+// hashFileNode sets node.SHA256 to the hash of the content the digest emits,
+// after redaction and transforms, so that it matches JSON content and bundled
+// files. Non-text files within the size limit are hashed on disk; oversized
+// files are not read again.
+func hashFileNode(node *FileNode) {
+	if node.Error != nil {
+		return
+	}
+	switch node.Type {
+	case NodeTypeFile, NodeTypeTruncated:
+		node.SHA256 = contentSHA256(node.Content)
+	case NodeTypeNotText:
+		sum, errSum := fsutil.FileSHA256(node.FullPath)
+		if errSum != nil {
+			node.Error = fmt.Errorf("error hashing file: %w", errSum)
+			return
+		}
+		node.SHA256 = sum
+	}
+}
+
+func readFileContent(node *FileNode, opts IngestionOptions) {
+	if opts.MaxFileSize > 0 && node.Size > opts.MaxFileSize {
+		node.Type = NodeTypeTooLarge
+		if opts.TruncateLarge.Enabled() {
+			truncateFileNode(node, opts)
+		}
+		return
+	}
+
+	isText, errText := fsutil.IsTextFile(node.FullPath)
+	if errText != nil {
+		node.Error = fmt.Errorf("error checking if file is text: %w", errText)
+		node.Type = NodeTypeNotText
+		return
+	}
+	if !isText {
+		node.Type = NodeTypeNotText
+		node.Description, _ = binmeta.Describe(node.FullPath)
+		return
+	}
+
+	// TODO: Handle .ipynb (currently read as plain text)
+	content, errRead := fsutil.ReadFileContent(node.FullPath)
+	if errRead != nil {
+		node.Error = fmt.Errorf("error reading file content: %w", errRead)
+		return
+	}
+	node.Content = content
+}
+
 func isPathMatchWithInfo(relativePath string, isDir bool, patterns []string) bool {
 	normalizedPath := filepath.ToSlash(relativePath)
 	normalizedPath = strings.TrimPrefix(normalizedPath, "./")
@@ -411,3 +492,8 @@ func sortNodes(nodes []*FileNode) {
 		return strings.ToLower(nodeI.Name) < strings.ToLower(nodeJ.Name)
 	})
 }
+
+func contentSHA256(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,11 @@
 package digest
 
 import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"strings"
 	"testing"
 )
 
@@ -106,3 +111,78 @@ func TestShouldProcessDirForInclude(t *testing.T) {
 		})
 	}
 }
+
+func TestNonTextDescription(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+	dir := writeTestTree(t, map[string]string{
+		"logo.png": buf.String(),
+		"blob.bin": "\x00\x01\x02\x03",
+		"main.go":  "package main\n",
+	})
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+
+	want := fileSeparator + "File: logo.png (non-text - content not included)\n" + fileSeparator + "PNG image, 4x3\n"
+	if !strings.Contains(r.FileContents, want) {
+		t.Errorf("expected %q in content:\n%s", want, r.FileContents)
+	}
+	entries, err := ParseDigest([]byte(r.FileContents))
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	for _, e := range entries {
+		if e.Path == "logo.png" && e.Placeholder != "non-text - content not included" {
+			t.Errorf("restored entry %+v, want a placeholder", e)
+		}
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	descriptions := make(map[string]string)
+	for _, f := range out.Files {
+		descriptions[f.Path] = f.Description
+	}
+	if descriptions["logo.png"] != "PNG image, 4x3" || descriptions["blob.bin"] != "" || descriptions["main.go"] != "" {
+		t.Errorf("descriptions = %v", descriptions)
+	}
+}
+
+func TestFileHashes(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"config.go": "package config\n\nconst key = \"" + testAWSKeyID + "\"\n",
+		"blob.bin":  "\x00\x01\x02\x03",
+		"big.txt":   strings.Repeat("x", 2048),
+	})
+	r, err := ProcessSource(IngestionOptions{Source: dir, MaxFileSize: 1024, Secrets: SecretsRedact})
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+
+	nodes := make(map[string]*FileNode)
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		nodes[node.Path] = node
+		return true
+	})
+	if config := nodes["config.go"]; strings.Contains(config.Content, testAWSKeyID) || config.SHA256 != contentSHA256(config.Content) {
+		t.Errorf("config.go sha256 does not describe the redacted content %q", config.Content)
+	}
+	if blob := nodes["blob.bin"]; blob.SHA256 != contentSHA256("\x00\x01\x02\x03") {
+		t.Errorf("blob.bin sha256 = %q, want the hash of the file", blob.SHA256)
+	}
+	if big := nodes["big.txt"]; big.Type != NodeTypeTooLarge || big.SHA256 != "" {
+		t.Errorf("big.txt type %v sha256 %q, want an unhashed oversized file", big.Type, big.SHA256)
+	}
+}
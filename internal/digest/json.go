@@ -2,24 +2,105 @@ package digest
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/fs"
 	"path/filepath"
+	"time"
+
+	"github.com/ga1az/pathdigest/internal/encutil"
+	"github.com/ga1az/pathdigest/internal/fsutil"
+	"github.com/ga1az/pathdigest/internal/langutil"
 )
 
+// JSONSchemaVersion is bumped whenever the JSON output changes in a way
+// that existing consumers may need to handle: a field is removed, renamed,
+// retyped or becomes required. Adding optional fields does not bump it.
+const JSONSchemaVersion = 1
+
 type JSONOutput struct {
-	Summary JSONSummary  `json:"summary"`
-	Tree    []*JSONNode  `json:"tree"`
-	Files   []JSONFile   `json:"files"`
-	GitInfo *JSONGitInfo `json:"git_info,omitempty"`
+	SchemaVersion int            `json:"schema_version"`
+	Summary       JSONSummary    `json:"summary"`
+	Tree          []*JSONNode    `json:"tree"`
+	Files         []JSONFile     `json:"files"`
+	GitInfo       *JSONGitInfo   `json:"git_info,omitempty"`
+	Dependencies  []JSONManifest `json:"dependencies,omitempty"`
+}
+
+// JSONManifest lists the direct dependencies declared in one manifest file.
+type JSONManifest struct {
+	Path         string           `json:"path"`
+	Ecosystem    string           `json:"ecosystem"`
+	Lockfile     string           `json:"lockfile,omitempty"`
+	Error        string           `json:"error,omitempty"`
+	Dependencies []JSONDependency `json:"dependencies"`
+}
+
+// JSONDependency is a direct dependency. version is the resolved version
+// and is omitted when neither the manifest nor a lockfile pins one.
+type JSONDependency struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Dev        bool   `json:"dev,omitempty"`
 }
 
 type JSONSummary struct {
-	Source          string   `json:"source"`
-	TotalFiles      int      `json:"total_files"`
-	TotalSize       int64    `json:"total_size"`
-	TotalSizeHuman  string   `json:"total_size_human"`
-	ExcludePatterns []string `json:"exclude_patterns"`
-	IncludePatterns []string `json:"include_patterns"`
-	MaxFileSize     int64    `json:"max_file_size"`
+	Source          string          `json:"source"`
+	TotalFiles      int             `json:"total_files"`
+	TotalSize       int64           `json:"total_size"`
+	TotalSizeHuman  string          `json:"total_size_human"`
+	ExcludePatterns []string        `json:"exclude_patterns"`
+	IncludePatterns []string        `json:"include_patterns"`
+	MaxFileSize     int64           `json:"max_file_size"`
+	TotalLines      int             `json:"total_lines,omitempty"`
+	EstimatedTokens int             `json:"estimated_tokens"`
+	Transforms      []JSONTransform `json:"transforms,omitempty"`
+	LicenseHeaders  []JSONLicense   `json:"license_headers,omitempty"`
+	SecretFindings  int             `json:"secret_findings,omitempty"`
+	PIIRedactions   int             `json:"pii_redactions,omitempty"`
+	Duplicates      *JSONDuplicates `json:"duplicates,omitempty"`
+	ModelFit        *JSONModelFit   `json:"model_fit,omitempty"`
+}
+
+// JSONSecret is a secret found in a file. The secret itself is never
+// included; fingerprint is its SHA-256.
+type JSONSecret struct {
+	Rule        string `json:"rule"`
+	Line        int    `json:"line"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// JSONDuplicates reports the files whose content was left out because an
+// earlier file has the same content.
+type JSONDuplicates struct {
+	Files       int   `json:"files"`
+	TokensSaved int   `json:"tokens_saved"`
+	BytesSaved  int64 `json:"bytes_saved"`
+}
+
+// JSONLicense is a distinct license header removed from files.
+type JSONLicense struct {
+	Text  string `json:"text"`
+	Files int    `json:"files"`
+}
+
+// JSONTransform reports the effect of a content transform such as comment
+// stripping, per file or summed over the digest.
+type JSONTransform struct {
+	Name         string `json:"name"`
+	Files        int    `json:"files,omitempty"`
+	TokensBefore int    `json:"tokens_before"`
+	TokensAfter  int    `json:"tokens_after"`
+}
+
+type JSONModelFit struct {
+	Model             string  `json:"model"`
+	Tokens            int     `json:"tokens,omitempty"`
+	ContextWindow     int     `json:"context_window"`
+	InputPricePerMTok float64 `json:"input_price_per_mtok"`
+	Fits              bool    `json:"fits"`
+	PercentUsed       float64 `json:"percent_used"`
+	EstimatedCost     float64 `json:"estimated_cost_usd"`
 }
 
 type JSONNode struct {
@@ -27,14 +108,48 @@ type JSONNode struct {
 	Path     string      `json:"path"`
 	Type     string      `json:"type"`
 	Size     int64       `json:"size,omitempty"`
+	Error    string      `json:"error,omitempty"`
 	Children []*JSONNode `json:"children,omitempty"`
 }
 
 type JSONFile struct {
-	Path    string `json:"path"`
-	Size    int64  `json:"size"`
-	Type    string `json:"type"`
-	Content string `json:"content"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Type       string `json:"type"`
+	Content    string `json:"content"`
+	Mode       string `json:"mode,omitempty"`
+	Executable bool   `json:"executable,omitempty"`
+	ModTime    string `json:"mtime,omitempty"`
+	SHA256     string `json:"sha256,omitempty"`
+	LineCount  int    `json:"line_count,omitempty"`
+	Language   string `json:"language,omitempty"`
+	Encoding   string `json:"encoding,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Description summarizes a recognized non-text file.
+	Description string          `json:"description,omitempty"`
+	Truncation  *JSONTruncation `json:"truncation,omitempty"`
+	Transforms  []JSONTransform `json:"transforms,omitempty"`
+	Secrets     []JSONSecret    `json:"secrets,omitempty"`
+	// PIIRedactions counts the placeholders inserted per label.
+	PIIRedactions map[string]int `json:"pii_redactions,omitempty"`
+	Lines         []JSONLine     `json:"lines,omitempty"`
+	// DuplicateOf is the path of an earlier file with the same content; the
+	// content itself is then left empty.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+}
+
+// JSONLine is one numbered content line, emitted with --line-numbers. Lines
+// omitted by truncation are absent, so the numbers skip over them.
+type JSONLine struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+type JSONTruncation struct {
+	HeadLines    int   `json:"head_lines"`
+	TailLines    int   `json:"tail_lines"`
+	OmittedLines int   `json:"omitted_lines"`
+	OmittedBytes int64 `json:"omitted_bytes"`
 }
 
 type JSONGitInfo struct {
@@ -46,31 +161,106 @@ type JSONGitInfo struct {
 }
 
 func (r *Result) FormatJSON(opts IngestionOptions) ([]byte, error) {
-	output := JSONOutput{
-		Summary: JSONSummary{
-			Source:          opts.Source,
-			TotalFiles:      r.TotalFiles,
-			TotalSize:       r.TotalSize,
-			TotalSizeHuman:  formatBytes(r.TotalSize),
-			ExcludePatterns: opts.ExcludePatterns,
-			IncludePatterns: opts.IncludePatterns,
-			MaxFileSize:     opts.MaxFileSize,
-		},
-		Tree:  buildJSONTree(r.RootNode),
-		Files: gatherJSONFiles(r.RootNode),
-	}
-
-	if r.GitInfo != nil {
-		output.GitInfo = &JSONGitInfo{
-			RepoURL:  r.GitInfo.RepoURL,
-			Branch:   r.GitInfo.Branch,
-			Commit:   r.GitInfo.Commit,
-			User:     r.GitInfo.User,
-			RepoName: r.GitInfo.RepoName,
+	return json.MarshalIndent(r.buildJSONOutput(opts), "", "  ")
+}
+
+// FormatYAML renders the JSONOutput schema as YAML.
+func (r *Result) FormatYAML(opts IngestionOptions) ([]byte, error) {
+	return encutil.MarshalYAML(r.buildJSONOutput(opts))
+}
+
+// FormatTOML renders the JSONOutput schema as TOML.
+func (r *Result) FormatTOML(opts IngestionOptions) ([]byte, error) {
+	return encutil.MarshalTOML(r.buildJSONOutput(opts))
+}
+
+// buildJSONOutput assembles the JSONOutput document shared by all structured
+// output formats.
+func (r *Result) buildJSONOutput(opts IngestionOptions) JSONOutput {
+	return JSONOutput{
+		SchemaVersion: JSONSchemaVersion,
+		Summary:       r.jsonSummary(opts),
+		Tree:          buildJSONTree(r.RootNode),
+		Files:         gatherJSONFiles(r.contentNodes(opts), opts),
+		GitInfo:       r.jsonGitInfo(),
+		Dependencies:  r.jsonDependencies(),
+	}
+}
+
+func (r *Result) jsonDependencies() []JSONManifest {
+	var manifests []JSONManifest
+	for _, m := range r.Dependencies {
+		jm := JSONManifest{Path: m.Path, Ecosystem: m.Ecosystem, Lockfile: m.Lockfile, Dependencies: []JSONDependency{}}
+		if m.Err != nil {
+			jm.Error = m.Err.Error()
+		}
+		for _, d := range m.Dependencies {
+			jm.Dependencies = append(jm.Dependencies, JSONDependency{Name: d.Name, Constraint: d.Constraint, Version: d.Version, Dev: d.Dev})
+		}
+		manifests = append(manifests, jm)
+	}
+	return manifests
+}
+
+func (r *Result) jsonSummary(opts IngestionOptions) JSONSummary {
+	summary := JSONSummary{
+		Source:          opts.Source,
+		TotalFiles:      r.TotalFiles,
+		TotalSize:       r.TotalSize,
+		TotalSizeHuman:  formatBytes(r.TotalSize),
+		ExcludePatterns: opts.ExcludePatterns,
+		IncludePatterns: opts.IncludePatterns,
+		MaxFileSize:     opts.MaxFileSize,
+		EstimatedTokens: r.TokenCount,
+	}
+	if opts.LineNumbers {
+		summary.TotalLines = r.totalLines(opts)
+	}
+	summary.SecretFindings = len(r.SecretFindings())
+	for _, red := range r.PIIRedactions() {
+		summary.PIIRedactions += piiTotal(red.Counts)
+	}
+	for _, h := range r.licenseHeaders() {
+		summary.LicenseHeaders = append(summary.LicenseHeaders, JSONLicense{Text: h.Text, Files: h.Files})
+	}
+	if total := r.dedupeTotal(); total.Files > 0 {
+		summary.Duplicates = &JSONDuplicates{Files: total.Files, TokensSaved: total.TokensSaved, BytesSaved: total.BytesSaved}
+	}
+	for _, total := range r.transformTotals() {
+		summary.Transforms = append(summary.Transforms, JSONTransform{
+			Name:         total.Name,
+			Files:        total.Files,
+			TokensBefore: total.TokensBefore,
+			TokensAfter:  total.TokensAfter,
+		})
+	}
+
+	if opts.Model != nil {
+		fit := EstimateModelFit(*opts.Model, r.fitTokens())
+		summary.ModelFit = &JSONModelFit{
+			Model:             fit.Model.Name,
+			Tokens:            fit.Tokens,
+			ContextWindow:     fit.Model.ContextWindow,
+			InputPricePerMTok: fit.Model.InputPricePerMTok,
+			Fits:              fit.Fits,
+			PercentUsed:       fit.PercentUsed,
+			EstimatedCost:     fit.EstimatedCost,
 		}
 	}
+	return summary
+}
 
-	return json.MarshalIndent(output, "", "  ")
+func (r *Result) jsonGitInfo() *JSONGitInfo {
+	if r.GitInfo == nil {
+		return nil
+	}
+	return &JSONGitInfo{
+		RepoURL:  r.GitInfo.RepoURL,
+		Branch:   r.GitInfo.Branch,
+		Commit:   r.GitInfo.Commit,
+		User:     r.GitInfo.User,
+		RepoName: r.GitInfo.RepoName,
+	}
 }
 
 func buildJSONTree(node *FileNode) []*JSONNode {
@@ -96,6 +286,9 @@ func fileNodeToJSON(node *FileNode) *JSONNode {
 		Type: string(node.Type),
 		Size: node.Size,
 	}
+	if node.Error != nil {
+		jn.Error = node.Error.Error()
+	}
 
 	if node.Type == NodeTypeDir && node.Children != nil {
 		jn.Children = make([]*JSONNode, 0, len(node.Children))
@@ -107,32 +300,73 @@ func fileNodeToJSON(node *FileNode) *JSONNode {
 	return jn
 }
 
-func gatherJSONFiles(node *FileNode) []JSONFile {
+func gatherJSONFiles(nodes []*FileNode, opts IngestionOptions) []JSONFile {
 	var files []JSONFile
-	gatherJSONFilesRecursive(node, &files)
+	for _, node := range nodes {
+		files = append(files, fileNodeToJSONFile(node, opts))
+	}
 	return files
 }
 
-func gatherJSONFilesRecursive(node *FileNode, files *[]JSONFile) {
-	if node.Type == NodeTypeFile {
-		f := JSONFile{
-			Path:    filepath.ToSlash(node.Path),
-			Size:    node.Size,
-			Type:    string(node.Type),
-			Content: node.Content, // always include, even if empty
-		}
-		*files = append(*files, f)
-	} else if node.Type == NodeTypeNotText || node.Type == NodeTypeTooLarge {
-		*files = append(*files, JSONFile{
-			Path: filepath.ToSlash(node.Path),
-			Size: node.Size,
-			Type: string(node.Type),
-		})
+func fileNodeToJSONFile(node *FileNode, opts IngestionOptions) JSONFile {
+	f := JSONFile{
+		Path:     filepath.ToSlash(node.Path),
+		Size:     node.Size,
+		Type:     string(node.Type),
+		SHA256:   node.SHA256,
+		Language: langutil.Detect(node.Path),
+	}
+	if node.Mode != 0 {
+		f.Mode = jsonFileMode(node.Mode)
+		f.Executable = node.Mode.Perm()&0111 != 0
+	}
+	if !node.ModTime.IsZero() {
+		f.ModTime = node.ModTime.UTC().Format(time.RFC3339)
+	}
+	if node.Error != nil {
+		f.Error = node.Error.Error()
+	}
+	if node.Type == NodeTypeNotText || node.Type == NodeTypeTooLarge {
+		f.Description = node.Description
+		return f
 	}
 
-	if node.Type == NodeTypeDir {
-		for _, child := range node.Children {
-			gatherJSONFilesRecursive(child, files)
+	if node.DuplicateOf != "" {
+		f.DuplicateOf = node.DuplicateOf
+		return f
+	}
+
+	f.Content = node.Content // always include, even if empty
+	f.LineCount = fileLineCount(node)
+	if node.Content != "" {
+		f.Encoding = fsutil.DetectEncoding([]byte(node.Content))
+	}
+	if node.Truncation != nil {
+		f.Truncation = &JSONTruncation{
+			HeadLines:    node.Truncation.HeadLines,
+			TailLines:    node.Truncation.TailLines,
+			OmittedLines: node.Truncation.OmittedLines,
+			OmittedBytes: node.Truncation.OmittedBytes,
+		}
+	}
+	for _, s := range node.Secrets {
+		f.Secrets = append(f.Secrets, JSONSecret{Rule: s.RuleID, Line: s.Line, Fingerprint: s.Fingerprint})
+	}
+	f.PIIRedactions = node.PIIRedactions
+	for _, stat := range node.Transforms {
+		f.Transforms = append(f.Transforms, JSONTransform{Name: stat.Name, TokensBefore: stat.TokensBefore, TokensAfter: stat.TokensAfter})
+	}
+	if opts.LineNumbers {
+		for _, line := range contentLines(node) {
+			if line.Number > 0 {
+				f.Lines = append(f.Lines, JSONLine{Number: line.Number, Text: line.Text})
+			}
 		}
 	}
+	return f
+}
+
+// jsonFileMode formats the permission bits of mode in octal, e.g. "0644".
+func jsonFileMode(mode fs.FileMode) string {
+	return fmt.Sprintf("%04o", mode.Perm())
 }
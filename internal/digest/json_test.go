@@ -2,7 +2,9 @@ package digest
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/ga1az/pathdigest/internal/gitutil"
 )
@@ -384,3 +386,62 @@ func containsN(s, substr string, n int) bool {
 	}
 	return count >= n
 }
+
+func TestFormatJSON_FileMetadata(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	root := &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{
+				Name: "run.sh", Path: "run.sh", Type: NodeTypeFile,
+				Size: 21, Mode: 0755, ModTime: modTime,
+				Content: "#!/bin/sh\necho héllo\n",
+				SHA256:  "abc123",
+			},
+			{
+				Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText,
+				Size: 10, Mode: 0644, ModTime: modTime,
+				Error: errors.New("error checking if file is text: permission denied"),
+			},
+		},
+	}
+	result := &Result{RootNode: root, TotalFiles: 2}
+
+	data, err := result.FormatJSON(IngestionOptions{Source: "."})
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var output JSONOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if output.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", output.SchemaVersion, JSONSchemaVersion)
+	}
+
+	script := output.Files[0]
+	if script.Mode != "0755" || !script.Executable {
+		t.Errorf("run.sh mode = %q executable = %v, want 0755 and true", script.Mode, script.Executable)
+	}
+	if script.ModTime != "2024-05-01T12:30:00Z" {
+		t.Errorf("run.sh mtime = %q", script.ModTime)
+	}
+	if script.SHA256 != "abc123" || script.LineCount != 2 {
+		t.Errorf("run.sh sha256 = %q line_count = %d", script.SHA256, script.LineCount)
+	}
+	if script.Language != "bash" || script.Encoding != "utf-8" {
+		t.Errorf("run.sh language = %q encoding = %q", script.Language, script.Encoding)
+	}
+
+	logo := output.Files[1]
+	if logo.Executable || logo.LineCount != 0 || logo.Encoding != "" {
+		t.Errorf("logo.png has unexpected text metadata: %+v", logo)
+	}
+	if logo.Error == "" {
+		t.Error("logo.png error was dropped")
+	}
+	if output.Tree[1].Error == "" {
+		t.Error("tree node error was dropped")
+	}
+}
@@ -0,0 +1,52 @@
+package digest
+
+import (
+	"fmt"
+	"os"
+)
+
+// LoadLicenseTemplate reads a license header file for
+// IngestionOptions.LicenseTemplate.
+func LoadLicenseTemplate(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read license template: %w", err)
+	}
+	return string(data), nil
+}
+
+// licenseHeader is a distinct license header and the number of files it was
+// removed from.
+type licenseHeader struct {
+	Text  string
+	Files int
+}
+
+// licenseHeaders returns the distinct license headers removed during
+// ingestion, in tree order of their first occurrence.
+func (r *Result) licenseHeaders() []licenseHeader {
+	var headers []licenseHeader
+	index := make(map[string]int)
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		if node.LicenseHeader == "" {
+			return true
+		}
+		i, ok := index[node.LicenseHeader]
+		if !ok {
+			i = len(headers)
+			index[node.LicenseHeader] = i
+			headers = append(headers, licenseHeader{Text: node.LicenseHeader})
+		}
+		headers[i].Files++
+		return true
+	})
+	return headers
+}
+
+func (r *Result) licenseSummaryRows() []summaryRow {
+	var rows []summaryRow
+	for _, h := range r.licenseHeaders() {
+		rows = append(rows, summaryRow{"License header", fmt.Sprintf("%s (%d files)", h.Text, h.Files)})
+	}
+	return rows
+}
@@ -0,0 +1,126 @@
+package digest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// errLineNumbersTransformed is returned when line numbers are requested
+// together with a content transform: transformed lines no longer match the
+// lines of the original file, so their numbers could not be cited.
+var errLineNumbersTransformed = errors.New("line numbers cannot be combined with --strip-comments, --compact, --outline or --strip-license-headers: numbers would not match the original file")
+
+// CheckLineNumbers reports whether opts can number content lines as in the
+// original files.
+func CheckLineNumbers(opts IngestionOptions) error {
+	if opts.LineNumbers && len(contentTransforms(opts)) > 0 {
+		return errLineNumbersTransformed
+	}
+	return nil
+}
+
+// contentLine is one line of a file's content. Number is the line's position
+// in the original file; it is 0 for the marker that replaces truncated lines.
+type contentLine struct {
+	Number int
+	Text   string
+}
+
+// contentLines splits node.Content into lines numbered as in the original
+// file. For truncated files the tail continues after the omitted lines.
+func contentLines(node *FileNode) []contentLine {
+	if node.Content == "" {
+		return nil
+	}
+	texts := strings.Split(strings.TrimSuffix(node.Content, "\n"), "\n")
+	lines := make([]contentLine, 0, len(texts))
+
+	markerAt, tailStart := -1, 0
+	if t := node.Truncation; t != nil && len(texts) == t.HeadLines+1+t.TailLines {
+		markerAt = t.HeadLines
+		tailStart = t.HeadLines + t.OmittedLines + 1
+	}
+
+	for i, text := range texts {
+		line := contentLine{Number: i + 1, Text: text}
+		switch {
+		case i == markerAt:
+			line.Number = 0
+		case markerAt >= 0 && i > markerAt:
+			line.Number = tailStart + (i - markerAt - 1)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fileLineCount returns the number of lines in the original file, including
+// lines omitted by truncation.
+func fileLineCount(node *FileNode) int {
+	if t := node.Truncation; t != nil {
+		return t.HeadLines + t.OmittedLines + t.TailLines
+	}
+	if node.Content == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(node.Content, "\n"), "\n") + 1
+}
+
+// countLines sums the original line counts of all text files below node.
+func countLines(node *FileNode) int {
+	if node == nil {
+		return 0
+	}
+	total := 0
+	if node.Type == NodeTypeFile || node.Type == NodeTypeTruncated {
+		total = fileLineCount(node)
+	}
+	for _, child := range node.Children {
+		total += countLines(child)
+	}
+	return total
+}
+
+// totalLines sums the original line counts of all text files in the digest.
+func (r *Result) totalLines(opts IngestionOptions) int {
+	total := 0
+	for _, node := range r.contentNodes(opts) {
+		if node.Type == NodeTypeFile || node.Type == NodeTypeTruncated {
+			total += fileLineCount(node)
+		}
+	}
+	return total
+}
+
+// numberLines prefixes each content line with its right-aligned line number.
+// The truncation marker keeps its text but gets a blank number column.
+func numberLines(node *FileNode) string {
+	lines := contentLines(node)
+	if len(lines) == 0 {
+		return ""
+	}
+	width := 1
+	for _, line := range lines {
+		width = max(width, len(strconv.Itoa(line.Number)))
+	}
+
+	var sb strings.Builder
+	for _, line := range lines {
+		if line.Number == 0 {
+			sb.WriteString(fmt.Sprintf("%*s | %s\n", width, "", line.Text))
+		} else {
+			sb.WriteString(fmt.Sprintf("%*d | %s\n", width, line.Number, line.Text))
+		}
+	}
+	return sb.String()
+}
+
+// displayContent returns node.Content as it should appear in the digest body.
+func displayContent(node *FileNode, opts IngestionOptions) string {
+	if opts.LineNumbers {
+		return numberLines(node)
+	}
+	return node.Content
+}
@@ -0,0 +1,115 @@
+package digest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNumberLines(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 12; i++ {
+		content.WriteString("line\n")
+	}
+	node := &FileNode{Type: NodeTypeFile, Content: content.String()}
+
+	got := numberLines(node)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("numberLines produced %d lines, want 12", len(lines))
+	}
+	if lines[0] != " 1 | line" || lines[11] != "12 | line" {
+		t.Errorf("unexpected padding: first %q, last %q", lines[0], lines[11])
+	}
+	if n := fileLineCount(node); n != 12 {
+		t.Errorf("fileLineCount = %d, want 12", n)
+	}
+}
+
+func TestNumberLines_Truncated(t *testing.T) {
+	node := &FileNode{
+		Type:       NodeTypeTruncated,
+		Content:    "a\nb\n... [truncated: 96 lines (1.0 KB) omitted] ...\ny\nz\n",
+		Truncation: &Truncation{HeadLines: 2, TailLines: 2, OmittedLines: 96, OmittedBytes: 1024},
+	}
+
+	want := "  1 | a\n" +
+		"  2 | b\n" +
+		"    | ... [truncated: 96 lines (1.0 KB) omitted] ...\n" +
+		" 99 | y\n" +
+		"100 | z\n"
+	if got := numberLines(node); got != want {
+		t.Errorf("numberLines =\n%s\nwant\n%s", got, want)
+	}
+	if n := fileLineCount(node); n != 100 {
+		t.Errorf("fileLineCount = %d, want 100", n)
+	}
+}
+
+func TestLineNumbersInOutputs(t *testing.T) {
+	root := &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "main.go", Path: "main.go", Type: NodeTypeFile, Content: "package main\n\nfunc main() {}\n"},
+		},
+	}
+	r := &Result{RootNode: root, TotalFiles: 1}
+	opts := IngestionOptions{Source: ".", LineNumbers: true}
+
+	r.FormatOutput(opts)
+	if !strings.Contains(r.FileContents, "1 | package main\n2 | \n3 | func main() {}\n") {
+		t.Errorf("text output is not numbered:\n%s", r.FileContents)
+	}
+	if !strings.Contains(r.Summary, "Total lines: 3\n") {
+		t.Errorf("summary lacks total line count:\n%s", r.Summary)
+	}
+
+	if md := string(r.FormatMarkdown(opts)); !strings.Contains(md, "```go\n1 | package main\n") {
+		t.Errorf("markdown output is not numbered:\n%s", md)
+	}
+	if x := string(r.FormatXML(opts)); !strings.Contains(x, "<![CDATA[1 | package main\n") {
+		t.Errorf("XML output is not numbered:\n%s", x)
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	f := out.Files[0]
+	if f.Content != "package main\n\nfunc main() {}\n" {
+		t.Errorf("JSON content should stay unnumbered, got %q", f.Content)
+	}
+	if len(f.Lines) != 3 || f.Lines[2] != (JSONLine{Number: 3, Text: "func main() {}"}) {
+		t.Errorf("unexpected JSON lines: %+v", f.Lines)
+	}
+	if out.Summary.TotalLines != 3 {
+		t.Errorf("total_lines = %d, want 3", out.Summary.TotalLines)
+	}
+}
+
+func TestCheckLineNumbers(t *testing.T) {
+	if err := CheckLineNumbers(IngestionOptions{LineNumbers: true}); err != nil {
+		t.Errorf("plain --line-numbers rejected: %v", err)
+	}
+	if err := CheckLineNumbers(IngestionOptions{StripComments: true, Compact: true}); err != nil {
+		t.Errorf("transforms without line numbers rejected: %v", err)
+	}
+	for _, opts := range []IngestionOptions{
+		{LineNumbers: true, StripComments: true},
+		{LineNumbers: true, Compact: true},
+		{LineNumbers: true, Outline: []string{"go"}},
+		{LineNumbers: true, StripLicenseHeaders: true},
+	} {
+		if err := CheckLineNumbers(opts); err == nil {
+			t.Errorf("CheckLineNumbers(%+v) = nil, want an error", opts)
+		}
+	}
+
+	if _, err := ProcessSource(IngestionOptions{Source: t.TempDir(), LineNumbers: true, StripComments: true}); err == nil {
+		t.Error("ProcessSource accepted --line-numbers with --strip-comments")
+	}
+}
@@ -0,0 +1,130 @@
+package digest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+)
+
+const (
+	minFenceLength = 3
+)
+
+func (r *Result) FormatMarkdown(opts IngestionOptions) []byte {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", r.RootNode.Name))
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString("| Field | Value |\n")
+	sb.WriteString("| --- | --- |\n")
+	for _, row := range r.summaryRows(opts) {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", escapeMarkdownTableCell(row.Label), escapeMarkdownTableCell(row.Value)))
+	}
+	sb.WriteString("\n")
+
+	var sbTree strings.Builder
+	r.writeTree(&sbTree)
+	if sbTree.Len() > 0 {
+		sb.WriteString("## Directory Structure\n\n")
+		writeFencedBlock(&sb, sbTree.String(), "text")
+		sb.WriteString("\n")
+	}
+
+	if deps := formatDependencies(r.Dependencies); deps != "" {
+		sb.WriteString("## Dependencies\n\n")
+		writeFencedBlock(&sb, strings.TrimPrefix(deps, "Dependencies:\n"), "text")
+		sb.WriteString("\n")
+	}
+
+	nodes := r.contentNodes(opts)
+	if len(nodes) > 0 {
+		sb.WriteString("## Files\n")
+	}
+	for _, node := range nodes {
+		writeMarkdownFile(&sb, node, opts)
+	}
+
+	return []byte(sb.String())
+}
+
+func writeMarkdownFile(sb *strings.Builder, node *FileNode, opts IngestionOptions) {
+	path := filepath.ToSlash(node.Path)
+
+	switch node.Type {
+	case NodeTypeFile, NodeTypeTruncated:
+		if node.Type == NodeTypeFile && node.Content == "" {
+			return
+		}
+		if node.DuplicateOf != "" {
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n", markdownCodeSpan(path)))
+			sb.WriteString(fmt.Sprintf("_identical to %s_\n", markdownCodeSpan(node.DuplicateOf)))
+			return
+		}
+		heading := markdownCodeSpan(path)
+		if node.Type == NodeTypeTruncated {
+			heading += " (truncated)"
+		}
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", heading))
+		lang := langutil.Detect(path)
+		if lang == "" {
+			lang = "text"
+		}
+		writeFencedBlock(sb, displayContent(node, opts), lang)
+	case NodeTypeNotText, NodeTypeTooLarge:
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", markdownCodeSpan(path)))
+		sb.WriteString(fmt.Sprintf("_%s - content not included_\n", node.Type))
+		if node.Description != "" {
+			sb.WriteString("\n" + node.Description + "\n")
+		}
+	}
+}
+
+// writeFencedBlock writes content in a backtick fence that is longer than
+// any backtick run inside the content, so the block cannot be closed early.
+func writeFencedBlock(sb *strings.Builder, content, lang string) {
+	fence := markdownFence(content)
+	sb.WriteString(fence)
+	sb.WriteString(lang)
+	sb.WriteString("\n")
+	sb.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fence)
+	sb.WriteString("\n")
+}
+
+func markdownFence(content string) string {
+	return strings.Repeat("`", max(minFenceLength, longestBacktickRun(content)+1))
+}
+
+func longestBacktickRun(s string) int {
+	longest, current := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '`' {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	return longest
+}
+
+// markdownCodeSpan wraps s in an inline code span, padding it when s itself
+// starts or ends with a backtick.
+func markdownCodeSpan(s string) string {
+	ticks := strings.Repeat("`", longestBacktickRun(s)+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return ticks + " " + s + " " + ticks
+	}
+	return ticks + s + ticks
+}
+
+func escapeMarkdownTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
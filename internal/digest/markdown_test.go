@@ -0,0 +1,53 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownFence(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"plain text", "```"},
+		{"inline `code` here", "```"},
+		{"```go\nfmt.Println()\n```", "````"},
+		{"nested ````` fence", "``````"},
+	}
+
+	for _, tt := range tests {
+		if got := markdownFence(tt.content); got != tt.want {
+			t.Errorf("markdownFence(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	root := &FileNode{
+		Name: "project",
+		Path: ".",
+		Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "README.md", Path: "README.md", Type: NodeTypeFile, Size: 30, Content: "# Title\n\n```sh\nmake\n```\n"},
+			{Name: "main.go", Path: "main.go", Type: NodeTypeFile, Size: 13, Content: "package main"},
+			{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 500},
+		},
+	}
+	r := &Result{RootNode: root, TotalFiles: 3, TotalSize: 543}
+
+	out := string(r.FormatMarkdown(IngestionOptions{Source: "./project", ExcludePatterns: []string{"a|b"}}))
+
+	for _, want := range []string{
+		"| Source Directory | ./project |",
+		"| Exclude Patterns | a\\|b |",
+		"## Directory Structure\n\n```text\n└── project/\n",
+		"### `README.md`\n\n````markdown\n# Title\n\n```sh\nmake\n```\n````\n",
+		"### `main.go`\n\n```go\npackage main\n```\n",
+		"### `logo.png`\n\n_non-text - content not included_\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output missing %q\noutput:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModelProfile describes the context window and input pricing of an LLM.
+type ModelProfile struct {
+	Name              string  `json:"name"`
+	ContextWindow     int     `json:"context_window"`
+	InputPricePerMTok float64 `json:"input_price_per_mtok"`
+}
+
+// DefaultModelProfiles is the built-in table of model profiles. Prices are in
+// USD per million input tokens and can be overridden from a models file.
+var DefaultModelProfiles = []ModelProfile{
+	{Name: "claude-opus-4", ContextWindow: 200000, InputPricePerMTok: 15},
+	{Name: "claude-sonnet-4", ContextWindow: 200000, InputPricePerMTok: 3},
+	{Name: "claude-3-5-haiku", ContextWindow: 200000, InputPricePerMTok: 0.8},
+	{Name: "gpt-4.1", ContextWindow: 1047576, InputPricePerMTok: 2},
+	{Name: "gpt-4o", ContextWindow: 128000, InputPricePerMTok: 2.5},
+	{Name: "gpt-4o-mini", ContextWindow: 128000, InputPricePerMTok: 0.15},
+	{Name: "o3", ContextWindow: 200000, InputPricePerMTok: 2},
+	{Name: "gemini-2.5-pro", ContextWindow: 1048576, InputPricePerMTok: 1.25},
+	{Name: "gemini-2.5-flash", ContextWindow: 1048576, InputPricePerMTok: 0.3},
+}
+
+type modelsFile struct {
+	Models []ModelProfile `json:"models"`
+}
+
+// DefaultModelsFilePath returns the models file looked up when none is given
+// explicitly, e.g. ~/.config/pathdigest/models.json on Linux.
+func DefaultModelsFilePath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "pathdigest", "models.json")
+}
+
+// LoadModelProfiles returns the built-in profiles extended by the models file
+// at path. Entries in the file replace built-in profiles with the same name.
+// A missing file is not an error unless required is set.
+func LoadModelProfiles(path string, required bool) ([]ModelProfile, error) {
+	profiles := make(map[string]ModelProfile, len(DefaultModelProfiles))
+	for _, p := range DefaultModelProfiles {
+		profiles[p.Name] = p
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) || required {
+				return nil, fmt.Errorf("failed to read models file %s: %w", path, err)
+			}
+		} else {
+			var mf modelsFile
+			if err := json.Unmarshal(data, &mf); err != nil {
+				return nil, fmt.Errorf("failed to parse models file %s: %w", path, err)
+			}
+			for _, p := range mf.Models {
+				if p.Name == "" {
+					return nil, fmt.Errorf("models file %s: model entry without a name", path)
+				}
+				if p.ContextWindow <= 0 {
+					return nil, fmt.Errorf("models file %s: model %q needs a positive context_window", path, p.Name)
+				}
+				profiles[p.Name] = p
+			}
+		}
+	}
+
+	result := make([]ModelProfile, 0, len(profiles))
+	for _, p := range profiles {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// FindModelProfile looks up a profile by case-insensitive name.
+func FindModelProfile(profiles []ModelProfile, name string) (*ModelProfile, error) {
+	names := make([]string, 0, len(profiles))
+	for i := range profiles {
+		if strings.EqualFold(profiles[i].Name, name) {
+			return &profiles[i], nil
+		}
+		names = append(names, profiles[i].Name)
+	}
+	return nil, fmt.Errorf("unknown model %q. Known models: %s", name, strings.Join(names, ", "))
+}
+
+// ModelFit reports how a digest of the given size fits a model.
+type ModelFit struct {
+	Model         ModelProfile
+	Tokens        int
+	Fits          bool
+	PercentUsed   float64
+	EstimatedCost float64
+}
+
+func EstimateModelFit(model ModelProfile, tokens int) ModelFit {
+	fit := ModelFit{
+		Model:         model,
+		Tokens:        tokens,
+		Fits:          tokens <= model.ContextWindow,
+		EstimatedCost: float64(tokens) / 1e6 * model.InputPricePerMTok,
+	}
+	if model.ContextWindow > 0 {
+		fit.PercentUsed = float64(tokens) / float64(model.ContextWindow) * 100
+	}
+	return fit
+}
+
+func (f ModelFit) summaryRows() []summaryRow {
+	status := "fits"
+	if !f.Fits {
+		status = "does not fit"
+	}
+	return []summaryRow{
+		{"Model", fmt.Sprintf("%s (%d token context)", f.Model.Name, f.Model.ContextWindow)},
+		{"Prompt tokens", fmt.Sprintf("%d", f.Tokens)},
+		{"Context used", fmt.Sprintf("%.1f%% (%s)", f.PercentUsed, status)},
+		{"Estimated input cost", fmt.Sprintf("$%.4f", f.EstimatedCost)},
+	}
+}
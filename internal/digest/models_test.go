@@ -0,0 +1,130 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadModelProfiles_FileOverridesAndExtends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	data := `{"models": [
+		{"name": "claude-sonnet-4", "context_window": 1000000, "input_price_per_mtok": 6},
+		{"name": "local-llama", "context_window": 8192, "input_price_per_mtok": 0}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write models file: %v", err)
+	}
+
+	profiles, err := LoadModelProfiles(path, true)
+	if err != nil {
+		t.Fatalf("LoadModelProfiles returned error: %v", err)
+	}
+
+	sonnet, err := FindModelProfile(profiles, "claude-sonnet-4")
+	if err != nil {
+		t.Fatalf("FindModelProfile(claude-sonnet-4) returned error: %v", err)
+	}
+	if sonnet.ContextWindow != 1000000 || sonnet.InputPricePerMTok != 6 {
+		t.Errorf("claude-sonnet-4 = %+v, want overridden values", *sonnet)
+	}
+	if _, err := FindModelProfile(profiles, "LOCAL-LLAMA"); err != nil {
+		t.Errorf("FindModelProfile(LOCAL-LLAMA) returned error: %v", err)
+	}
+	if _, err := FindModelProfile(profiles, "gpt-4o"); err != nil {
+		t.Errorf("built-in gpt-4o missing after loading file: %v", err)
+	}
+}
+
+func TestLoadModelProfiles_MissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nope.json")
+	if _, err := LoadModelProfiles(missing, false); err != nil {
+		t.Errorf("optional missing file returned error: %v", err)
+	}
+	if _, err := LoadModelProfiles(missing, true); err == nil {
+		t.Error("required missing file returned no error")
+	}
+}
+
+func TestEstimateModelFit(t *testing.T) {
+	model := ModelProfile{Name: "m", ContextWindow: 200000, InputPricePerMTok: 3}
+
+	fit := EstimateModelFit(model, 50000)
+	if !fit.Fits || math.Abs(fit.PercentUsed-25) > 1e-9 || math.Abs(fit.EstimatedCost-0.15) > 1e-9 {
+		t.Errorf("EstimateModelFit(50000) = %+v, want fits, 25%%, $0.15", fit)
+	}
+
+	if EstimateModelFit(model, 200001).Fits {
+		t.Error("EstimateModelFit(200001) reports fit for a 200000 window")
+	}
+}
+
+func TestFormatJSON_ModelFit(t *testing.T) {
+	r := &Result{
+		RootNode:   &FileNode{Name: "a.txt", Path: ".", Type: NodeTypeFile, Content: "12345678"},
+		TotalFiles: 1,
+		TokenCount: 2,
+	}
+	opts := IngestionOptions{Source: "a.txt", Model: &ModelProfile{Name: "m", ContextWindow: 100, InputPricePerMTok: 1}}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var output JSONOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatalf("Failed to unmarshal JSON output: %v", err)
+	}
+	if output.Summary.EstimatedTokens != 2 {
+		t.Errorf("Summary.EstimatedTokens = %d, want 2", output.Summary.EstimatedTokens)
+	}
+	if output.Summary.ModelFit == nil || output.Summary.ModelFit.PercentUsed != 2 {
+		t.Errorf("Summary.ModelFit = %+v, want 2%% used", output.Summary.ModelFit)
+	}
+}
+
+func TestRender_ModelFitCountsOutput(t *testing.T) {
+	newResult := func() *Result {
+		return &Result{
+			RootNode: &FileNode{Name: "p", Path: ".", Type: NodeTypeDir, Children: []*FileNode{
+				{Name: "a.txt", Path: "a.txt", Type: NodeTypeFile, Content: "12345678"},
+			}},
+			TotalFiles: 1,
+			TokenCount: 2,
+		}
+	}
+	opts := IngestionOptions{Source: ".", Model: &ModelProfile{Name: "m", ContextWindow: 1000, InputPricePerMTok: 1}}
+
+	r := newResult()
+	f, _ := LookupFormatter("text")
+	var out bytes.Buffer
+	if err := Render(f, &out, r, opts); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if want := estimateTokens(out.String()); r.OutputTokens != want {
+		t.Errorf("text OutputTokens = %d, want %d", r.OutputTokens, want)
+	}
+	if want := fmt.Sprintf("Prompt tokens: %d\n", r.OutputTokens); !strings.Contains(r.Summary, want) {
+		t.Errorf("summary lacks %q:\n%s", want, r.Summary)
+	}
+
+	for _, name := range []string{"zip", "csv"} {
+		r := newResult()
+		f, _ := LookupFormatter(name)
+		if err := Render(f, io.Discard, r, opts); err != nil {
+			t.Fatalf("Render(%s) returned error: %v", name, err)
+		}
+		if r.OutputTokens != 0 || r.fitTokens() != 2 {
+			t.Errorf("%s fit tokens = %d (output %d), want the content count 2", name, r.fitTokens(), r.OutputTokens)
+		}
+		if fit := FormatModelFit(r, opts); !strings.Contains(fit, "Prompt tokens: 2\n") {
+			t.Errorf("%s model fit = %q, want 2 prompt tokens", name, fit)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+const (
+	ndjsonRecordHeader  = "header"
+	ndjsonRecordNode    = "node"
+	ndjsonRecordFile    = "file"
+	ndjsonRecordTrailer = "trailer"
+)
+
+type NDJSONHeader struct {
+	Record        string `json:"record"`
+	SchemaVersion int    `json:"schema_version"`
+	Source        string `json:"source"`
+}
+
+type NDJSONNode struct {
+	Record string `json:"record"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Size   int64  `json:"size,omitempty"`
+	Depth  int    `json:"depth"`
+	Error  string `json:"error,omitempty"`
+}
+
+type NDJSONFile struct {
+	Record string `json:"record"`
+	JSONFile
+}
+
+type NDJSONTrailer struct {
+	Record          string         `json:"record"`
+	TotalFiles      int            `json:"total_files"`
+	TotalSize       int64          `json:"total_size"`
+	EstimatedTokens int            `json:"estimated_tokens"`
+	NodeRecords     int            `json:"node_records"`
+	FileRecords     int            `json:"file_records"`
+	Summary         JSONSummary    `json:"summary"`
+	GitInfo         *JSONGitInfo   `json:"git_info,omitempty"`
+	Dependencies    []JSONManifest `json:"dependencies,omitempty"`
+}
+
+// ndjsonWriter encodes NDJSON records and counts them for the trailer.
+type ndjsonWriter struct {
+	enc   *json.Encoder
+	opts  IngestionOptions
+	files int
+	nodes int
+}
+
+func newNDJSONWriter(w io.Writer, opts IngestionOptions) *ndjsonWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w), opts: opts}
+}
+
+func (n *ndjsonWriter) header() error {
+	return n.enc.Encode(NDJSONHeader{Record: ndjsonRecordHeader, SchemaVersion: JSONSchemaVersion, Source: n.opts.Source})
+}
+
+func (n *ndjsonWriter) file(node *FileNode) error {
+	n.files++
+	return n.enc.Encode(NDJSONFile{Record: ndjsonRecordFile, JSONFile: fileNodeToJSONFile(node, n.opts)})
+}
+
+// finish writes a node record per tree entry and the trailer with the
+// summary, which is only complete once every file has been read.
+func (n *ndjsonWriter) finish(r *Result, summary JSONSummary) error {
+	var nodeErr error
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		rec := NDJSONNode{
+			Record: ndjsonRecordNode,
+			Name:   node.Name,
+			Path:   filepath.ToSlash(node.Path),
+			Type:   string(node.Type),
+			Size:   node.Size,
+			Depth:  node.Depth,
+		}
+		if node.Error != nil {
+			rec.Error = node.Error.Error()
+		}
+		nodeErr = n.enc.Encode(rec)
+		n.nodes++
+		return nodeErr == nil
+	})
+	if nodeErr != nil {
+		return nodeErr
+	}
+
+	return n.enc.Encode(NDJSONTrailer{
+		Record:          ndjsonRecordTrailer,
+		TotalFiles:      r.TotalFiles,
+		TotalSize:       r.TotalSize,
+		EstimatedTokens: r.TokenCount,
+		NodeRecords:     n.nodes,
+		FileRecords:     n.files,
+		Summary:         summary,
+		GitInfo:         r.jsonGitInfo(),
+		Dependencies:    r.jsonDependencies(),
+	})
+}
+
+// WriteNDJSON writes an ingested digest as newline-delimited JSON: a header
+// record, one record per file in content order, one record per tree node and
+// a trailer with totals and the summary. StreamNDJSON writes the same records
+// without holding all file content in memory.
+func (r *Result) WriteNDJSON(w io.Writer, opts IngestionOptions) error {
+	n := newNDJSONWriter(w, opts)
+	if err := n.header(); err != nil {
+		return err
+	}
+	for _, node := range r.contentNodes(opts) {
+		if err := n.file(node); err != nil {
+			return err
+		}
+	}
+	return n.finish(r, r.jsonSummary(opts))
+}
+
+// CanStreamNDJSON reports whether StreamNDJSON supports opts. Content order
+// other than the default, --dedupe and secrets mode "fail" need every file
+// before the first one can be written.
+func CanStreamNDJSON(opts IngestionOptions) bool {
+	return (opts.Order == "" || opts.Order == OrderAlphabetical) && len(opts.PriorityPatterns) == 0 &&
+		!opts.Dedupe && opts.Secrets != SecretsFail
+}
+
+// StreamNDJSON ingests opts.Source and writes each file record as soon as the
+// file has been read, then drops its content, so memory no longer grows with
+// the size of the repository. The records are the same as WriteNDJSON's.
+// The returned Result has no file content. Errors from ingestion are
+// returned with a nil Result; write errors with the partial Result.
+func StreamNDJSON(w io.Writer, opts IngestionOptions) (*Result, error) {
+	if !CanStreamNDJSON(opts) {
+		return nil, fmt.Errorf("ndjson streaming does not support this content order, --dedupe or --secrets %s", SecretsFail)
+	}
+	n := newNDJSONWriter(w, opts)
+	if err := n.header(); err != nil {
+		return nil, err
+	}
+
+	var writeErr error
+	tokens, lines := 0, 0
+	opts.onFile = func(node *FileNode) {
+		if writeErr != nil {
+			return
+		}
+		tokens += estimateTokens(node.Content)
+		if node.Type == NodeTypeFile || node.Type == NodeTypeTruncated {
+			lines += fileLineCount(node)
+		}
+		writeErr = n.file(node)
+		node.Content = ""
+	}
+
+	r, err := ProcessSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return r, writeErr
+	}
+	r.TokenCount = tokens
+	summary := r.jsonSummary(opts)
+	if opts.LineNumbers {
+		summary.TotalLines = lines
+	}
+	return r, n.finish(r, summary)
+}
+
+// walkNodes visits node and its descendants depth-first in tree order until
+// visit returns false.
+func walkNodes(node *FileNode, visit func(*FileNode) bool) bool {
+	if node == nil {
+		return true
+	}
+	if !visit(node) {
+		return false
+	}
+	for _, child := range node.Children {
+		if !walkNodes(child, visit) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package digest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWriteNDJSON_Records(t *testing.T) {
+	root := &FileNode{
+		Name: "project",
+		Path: ".",
+		Type: NodeTypeDir,
+		Children: []*FileNode{
+			{
+				Name: "src", Path: "src", Type: NodeTypeDir, Depth: 1,
+				Children: []*FileNode{
+					{Name: "main.go", Path: "src/main.go", Type: NodeTypeFile, Size: 13, Depth: 2, Content: "package main\n"},
+				},
+			},
+			{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 500, Depth: 1},
+			{Name: "secret.txt", Path: "secret.txt", Type: NodeTypeFile, Depth: 1, Error: errors.New("permission denied")},
+		},
+	}
+	r := &Result{RootNode: root, TotalFiles: 3, TotalSize: 513, TokenCount: 4}
+
+	var buf bytes.Buffer
+	if err := r.WriteNDJSON(&buf, IngestionOptions{Source: "."}); err != nil {
+		t.Fatalf("WriteNDJSON returned error: %v", err)
+	}
+
+	var kinds []string
+	var trailer NDJSONTrailer
+	var nodeErr string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var rec struct {
+			Record string `json:"record"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("line is not valid JSON: %v\n%s", err, scanner.Text())
+		}
+		kinds = append(kinds, rec.Record)
+		if rec.Record == ndjsonRecordTrailer {
+			json.Unmarshal(scanner.Bytes(), &trailer)
+		}
+		if rec.Record == ndjsonRecordNode {
+			var n NDJSONNode
+			json.Unmarshal(scanner.Bytes(), &n)
+			if n.Path == "secret.txt" {
+				nodeErr = n.Error
+			}
+		}
+		if rec.Record == ndjsonRecordFile {
+			var f NDJSONFile
+			json.Unmarshal(scanner.Bytes(), &f)
+			if f.Path == "src/main.go" && f.Content != "package main\n" {
+				t.Errorf("file record content = %q", f.Content)
+			}
+		}
+	}
+
+	want := []string{"header", "file", "file", "file", "node", "node", "node", "node", "node", "trailer"}
+	if len(kinds) != len(want) {
+		t.Fatalf("records = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("records = %v, want %v", kinds, want)
+		}
+	}
+	if trailer.TotalFiles != 3 || trailer.NodeRecords != 5 || trailer.FileRecords != 3 || trailer.EstimatedTokens != 4 {
+		t.Errorf("trailer = %+v", trailer)
+	}
+	if trailer.Summary.TotalFiles != 3 {
+		t.Errorf("trailer summary = %+v", trailer.Summary)
+	}
+	if nodeErr != "permission denied" {
+		t.Errorf("node error = %q, want %q", nodeErr, "permission denied")
+	}
+}
+
+func TestStreamNDJSON_MatchesWriteNDJSON(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"README.md":        "# demo\n",
+		"b.go":             "package demo\n\nfunc B() {}\n",
+		"A.go":             "package demo\n",
+		"internal/x/x.go":  "package x\n",
+		"internal/y.txt":   "one\ntwo\n",
+		"Docs/guide.md":    "guide\n",
+		"internal/x/z.bin": "\x00\x01\x02",
+	})
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1 << 20, LineNumbers: true}
+
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	var want bytes.Buffer
+	if err := r.WriteNDJSON(&want, opts); err != nil {
+		t.Fatalf("WriteNDJSON returned error: %v", err)
+	}
+
+	var got bytes.Buffer
+	streamed, err := StreamNDJSON(&got, opts)
+	if err != nil {
+		t.Fatalf("StreamNDJSON returned error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("streamed records differ from WriteNDJSON:\n got: %s\nwant: %s", got.String(), want.String())
+	}
+	if streamed.TokenCount != r.TokenCount {
+		t.Errorf("TokenCount = %d, want %d", streamed.TokenCount, r.TokenCount)
+	}
+	for _, node := range streamed.contentNodes(opts) {
+		if node.Content != "" {
+			t.Errorf("%s kept its content after streaming", node.Path)
+		}
+	}
+}
+
+func TestCanStreamNDJSON(t *testing.T) {
+	tests := []struct {
+		opts IngestionOptions
+		want bool
+	}{
+		{IngestionOptions{}, true},
+		{IngestionOptions{Order: OrderAlphabetical, Secrets: SecretsWarn}, true},
+		{IngestionOptions{Order: OrderSize}, false},
+		{IngestionOptions{PriorityPatterns: []string{"*.md"}}, false},
+		{IngestionOptions{Dedupe: true}, false},
+		{IngestionOptions{Secrets: SecretsFail}, false},
+	}
+	for _, tt := range tests {
+		if got := CanStreamNDJSON(tt.opts); got != tt.want {
+			t.Errorf("CanStreamNDJSON(%+v) = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
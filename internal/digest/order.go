@@ -0,0 +1,108 @@
+package digest
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	OrderAlphabetical     = "alphabetical"
+	OrderDocsFirst        = "docs-first"
+	OrderEntrypointsFirst = "entrypoints-first"
+	OrderSize             = "size"
+	OrderChurn            = "churn"
+)
+
+// OrderStrategies lists the accepted values for IngestionOptions.Order.
+var OrderStrategies = []string{
+	OrderAlphabetical,
+	OrderDocsFirst,
+	OrderEntrypointsFirst,
+	OrderSize,
+	OrderChurn,
+}
+
+func IsValidOrder(order string) bool {
+	if order == "" {
+		return true
+	}
+	for _, o := range OrderStrategies {
+		if o == order {
+			return true
+		}
+	}
+	return false
+}
+
+// contentNodes returns the nodes that appear in the content section, ordered
+// by opts.Order with opts.PriorityPatterns moved to the front. The tree itself
+// keeps the alphabetical order produced by sortNodes.
+func (r *Result) contentNodes(opts IngestionOptions) []*FileNode {
+	var nodes []*FileNode
+	collectContentNodes(r.RootNode, &nodes)
+
+	switch opts.Order {
+	case OrderDocsFirst:
+		sortByPatternRank(nodes, DocPatterns)
+	case OrderEntrypointsFirst:
+		sortByPatternRank(nodes, EntrypointPatterns)
+	case OrderSize:
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].Size < nodes[j].Size
+		})
+	case OrderChurn:
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return r.Churn[filepath.ToSlash(nodes[i].Path)] > r.Churn[filepath.ToSlash(nodes[j].Path)]
+		})
+	}
+
+	if len(opts.PriorityPatterns) > 0 {
+		sortByPatternRank(nodes, opts.PriorityPatterns)
+	}
+	return nodes
+}
+
+func collectContentNodes(node *FileNode, nodes *[]*FileNode) {
+	if node == nil {
+		return
+	}
+	switch node.Type {
+	case NodeTypeFile, NodeTypeTruncated, NodeTypeNotText, NodeTypeTooLarge:
+		*nodes = append(*nodes, node)
+	case NodeTypeDir:
+		for _, child := range node.Children {
+			collectContentNodes(child, nodes)
+		}
+	}
+}
+
+// sortByPatternRank stably moves nodes matching patterns to the front, ranked
+// by the first pattern they match and then by depth, so a top-level README
+// comes before nested ones.
+func sortByPatternRank(nodes []*FileNode, patterns []string) {
+	ranks := make(map[*FileNode]int, len(nodes))
+	for _, node := range nodes {
+		ranks[node] = patternRank(node.Path, patterns)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ri, rj := ranks[nodes[i]], ranks[nodes[j]]
+		if ri != rj {
+			return ri < rj
+		}
+		if ri == len(patterns) {
+			return false
+		}
+		return nodes[i].Depth < nodes[j].Depth
+	})
+}
+
+func patternRank(path string, patterns []string) int {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+	for i, pattern := range patterns {
+		if isPathMatchWithInfo(path, false, []string{pattern}) {
+			return i
+		}
+	}
+	return len(patterns)
+}
@@ -0,0 +1,88 @@
+package digest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func orderTestTree() *FileNode {
+	return &FileNode{
+		Name: "project",
+		Path: ".",
+		Type: NodeTypeDir,
+		Children: []*FileNode{
+			{
+				Name: "cmd", Path: "cmd", Type: NodeTypeDir, Depth: 1,
+				Children: []*FileNode{
+					{Name: "main.go", Path: "cmd/main.go", Type: NodeTypeFile, Size: 300, Depth: 2, Content: "package main\n"},
+				},
+			},
+			{
+				Name: "docs", Path: "docs", Type: NodeTypeDir, Depth: 1,
+				Children: []*FileNode{
+					{Name: "README.md", Path: "docs/README.md", Type: NodeTypeFile, Size: 50, Depth: 2, Content: "docs\n"},
+				},
+			},
+			{Name: "README.md", Path: "README.md", Type: NodeTypeFile, Size: 200, Depth: 1, Content: "# Project\n"},
+			{Name: "util.go", Path: "util.go", Type: NodeTypeFile, Size: 100, Depth: 1, Content: "package project\n"},
+		},
+	}
+}
+
+func contentPaths(nodes []*FileNode) []string {
+	paths := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		paths = append(paths, n.Path)
+	}
+	return paths
+}
+
+func TestContentNodes_Order(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     IngestionOptions
+		churn    map[string]int
+		expected []string
+	}{
+		{"alphabetical", IngestionOptions{Order: OrderAlphabetical}, nil,
+			[]string{"cmd/main.go", "docs/README.md", "README.md", "util.go"}},
+		{"docs-first", IngestionOptions{Order: OrderDocsFirst}, nil,
+			[]string{"README.md", "docs/README.md", "cmd/main.go", "util.go"}},
+		{"entrypoints-first", IngestionOptions{Order: OrderEntrypointsFirst}, nil,
+			[]string{"cmd/main.go", "docs/README.md", "README.md", "util.go"}},
+		{"size", IngestionOptions{Order: OrderSize}, nil,
+			[]string{"docs/README.md", "util.go", "README.md", "cmd/main.go"}},
+		{"churn", IngestionOptions{Order: OrderChurn}, map[string]int{"util.go": 7, "README.md": 2},
+			[]string{"util.go", "README.md", "cmd/main.go", "docs/README.md"}},
+		{"priority over alphabetical", IngestionOptions{PriorityPatterns: []string{"util.go", "*.md"}}, nil,
+			[]string{"util.go", "README.md", "docs/README.md", "cmd/main.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Result{RootNode: orderTestTree(), Churn: tt.churn}
+			got := contentPaths(r.contentNodes(tt.opts))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("contentNodes() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatOutput_PriorityKeepsTreeAlphabetical(t *testing.T) {
+	r := &Result{RootNode: orderTestTree(), TotalFiles: 4}
+	opts := IngestionOptions{Source: ".", PriorityPatterns: []string{"util.go"}}
+	r.FormatOutput(opts)
+
+	want := "Directory structure:\n" +
+		"└── project/\n" +
+		"    ├── cmd/\n" +
+		"    │   └── main.go\n" +
+		"    ├── docs/\n" +
+		"    │   └── README.md\n" +
+		"    ├── README.md\n" +
+		"    └── util.go\n"
+	if r.TreeStructure != want {
+		t.Errorf("TreeStructure =\n%s\nwant\n%s", r.TreeStructure, want)
+	}
+}
@@ -0,0 +1,96 @@
+package digest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/redact"
+)
+
+// PIIRule detects one kind of personal data for IngestionOptions.PIIRules.
+type PIIRule = redact.PIIRule
+
+// LoadPIIRules reads a PII config file for IngestionOptions.PIIRules.
+func LoadPIIRules(path string) ([]redact.PIIRule, error) {
+	return redact.LoadPIIRules(path)
+}
+
+// redactPII replaces the personal data in node's content with placeholders
+// and records how many were made per label. Like scanSecrets, it runs before
+// content transforms.
+func redactPII(node *FileNode, opts IngestionOptions) {
+	if opts.piiRedactor == nil || node.Content == "" {
+		return
+	}
+	node.Content, node.PIIRedactions = opts.piiRedactor.Redact(node.Content)
+}
+
+// PIIRedaction is the number of redactions made in one file.
+type PIIRedaction struct {
+	Path   string
+	Counts map[string]int
+}
+
+// PIIRedactions returns the files with redacted personal data in tree order.
+func (r *Result) PIIRedactions() []PIIRedaction {
+	var redactions []PIIRedaction
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		if len(node.PIIRedactions) > 0 {
+			path := filepath.ToSlash(node.Path)
+			if node == r.RootNode {
+				path = node.Name
+			}
+			redactions = append(redactions, PIIRedaction{Path: path, Counts: node.PIIRedactions})
+		}
+		return true
+	})
+	return redactions
+}
+
+// FormatPIIReport lists the redactions one file per line.
+func FormatPIIReport(redactions []PIIRedaction) string {
+	var sb strings.Builder
+	for _, r := range redactions {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", r.Path, formatPIICounts(r.Counts)))
+	}
+	return sb.String()
+}
+
+// formatPIICounts formats counts as "EMAIL 2, PHONE 1", ordered by label.
+func formatPIICounts(counts map[string]int) string {
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s %d", label, counts[label])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func piiTotal(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+func (r *Result) piiSummaryRows() []summaryRow {
+	redactions := r.PIIRedactions()
+	if len(redactions) == 0 {
+		return nil
+	}
+	totals := make(map[string]int)
+	for _, red := range redactions {
+		for label, n := range red.Counts {
+			totals[label] += n
+		}
+	}
+	value := fmt.Sprintf("%d in %d file(s) (%s)", piiTotal(totals), len(redactions), formatPIICounts(totals))
+	return []summaryRow{{"PII redacted", value}}
+}
@@ -0,0 +1,67 @@
+package digest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactPII(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"a.txt": "owner: jane@example.com\nbackup: ops@example.com\n",
+		"b.txt": "contact jane@example.com at 203.0.113.7\n",
+		"c.txt": "nothing personal\n",
+	})
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024, RedactPII: true}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+
+	if strings.Contains(r.FileContents, "@example.com") {
+		t.Errorf("email addresses were not redacted:\n%s", r.FileContents)
+	}
+	for _, want := range []string{"owner: <EMAIL_1>\nbackup: <EMAIL_2>\n", "contact <EMAIL_1> at <IPV4_1>\n"} {
+		if !strings.Contains(r.FileContents, want) {
+			t.Errorf("expected %q in content:\n%s", want, r.FileContents)
+		}
+	}
+	for _, want := range []string{"PII Redaction: enabled", "PII redacted: 4 in 2 file(s) (EMAIL 3, IPV4 1)"} {
+		if !strings.Contains(r.Summary, want) {
+			t.Errorf("expected %q in summary:\n%s", want, r.Summary)
+		}
+	}
+	if report := FormatPIIReport(r.PIIRedactions()); report != "a.txt: EMAIL 2\nb.txt: EMAIL 1, IPV4 1\n" {
+		t.Errorf("report = %q", report)
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out.Summary.PIIRedactions != 4 {
+		t.Errorf("pii_redactions = %d, want 4", out.Summary.PIIRedactions)
+	}
+	if got := out.Files[1].PIIRedactions; got["EMAIL"] != 1 || got["IPV4"] != 1 {
+		t.Errorf("b.txt pii_redactions = %v", got)
+	}
+	if out.Files[2].PIIRedactions != nil {
+		t.Errorf("c.txt pii_redactions = %v, want none", out.Files[2].PIIRedactions)
+	}
+}
+
+func TestRedactPII_OffByDefault(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{"a.txt": "owner: jane@example.com\n"})
+	r, err := ProcessSource(IngestionOptions{Source: dir, MaxFileSize: 1024})
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	if len(r.PIIRedactions()) != 0 {
+		t.Errorf("PII was redacted without RedactPII: %+v", r.PIIRedactions())
+	}
+}
@@ -0,0 +1,317 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	restorePlaceholderTruncated = "truncated - only partial content included"
+)
+
+var (
+	textDigestSeparator    = strings.TrimSuffix(fileSeparator, "\n")
+	textPlaceholderRegex   = regexp.MustCompile(`^(.*) \((.+ - content not included)\)$`)
+	textDuplicateRegex     = regexp.MustCompile(`^(.*) \(identical to (.+)\)$`)
+	textTruncatedSuffix    = " (truncated)"
+	textSingleFileTreeLine = regexp.MustCompile(`(?m)^File processed:\n└── (.+)$`)
+)
+
+// RestoreEntry is a file recovered from a digest. Entries with a Placeholder
+// carry no usable content and are reported instead of written.
+type RestoreEntry struct {
+	Path        string
+	Content     string
+	Placeholder string
+}
+
+// RestoreOptions controls how RestoreEntries writes files.
+type RestoreOptions struct {
+	DryRun    bool
+	Overwrite bool
+}
+
+// RestoreReport lists what RestoreEntries did (or would do, in a dry run).
+type RestoreReport struct {
+	Written      []RestoreEntry
+	Placeholders []RestoreEntry
+	Existing     []RestoreEntry
+}
+
+// ParseDigest parses a text or JSON digest back into file entries. Files
+// left out as duplicates get the content of the file they duplicate.
+func ParseDigest(data []byte) ([]RestoreEntry, error) {
+	var entries []RestoreEntry
+	var duplicateOf map[int]string
+	var err error
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		entries, duplicateOf, err = parseJSONDigest(data)
+	} else {
+		entries, duplicateOf, err = parseTextDigest(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	resolveDuplicates(entries, duplicateOf)
+	return entries, nil
+}
+
+// resolveDuplicates copies content into the entries at the indexes of
+// duplicateOf from the entries at the paths they map to.
+func resolveDuplicates(entries []RestoreEntry, duplicateOf map[int]string) {
+	byPath := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		if _, dup := duplicateOf[i]; !dup {
+			byPath[entry.Path] = i
+		}
+	}
+	for i, orig := range duplicateOf {
+		j, ok := byPath[orig]
+		switch {
+		case !ok:
+			entries[i].Placeholder = fmt.Sprintf("identical to %s, which is missing", orig)
+		case entries[j].Placeholder != "":
+			entries[i].Placeholder = fmt.Sprintf("identical to %s - %s", orig, entries[j].Placeholder)
+		default:
+			entries[i].Content = entries[j].Content
+		}
+	}
+}
+
+func parseJSONDigest(data []byte) ([]RestoreEntry, map[int]string, error) {
+	var output JSONOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON digest: %w", err)
+	}
+
+	singleFileName := ""
+	if len(output.Tree) == 1 && output.Tree[0].Path == "." {
+		singleFileName = output.Tree[0].Name
+	}
+
+	entries := make([]RestoreEntry, 0, len(output.Files))
+	duplicateOf := make(map[int]string)
+	for _, f := range output.Files {
+		entry := RestoreEntry{Path: f.Path}
+		if entry.Path == "." && singleFileName != "" {
+			entry.Path = singleFileName
+		}
+		switch {
+		case f.DuplicateOf != "":
+			duplicateOf[len(entries)] = f.DuplicateOf
+		case FileNodeType(f.Type) == NodeTypeFile:
+			entry.Content = f.Content
+		case FileNodeType(f.Type) == NodeTypeTruncated:
+			entry.Placeholder = restorePlaceholderTruncated
+		default:
+			entry.Placeholder = fmt.Sprintf("%s - content not included", f.Type)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, duplicateOf, nil
+}
+
+// parseTextDigest splits a text digest on its "File:" headers. Each content
+// block was written with one extra blank line, which is removed again. The
+// framing is not reversible: content always ends with a newline, and empty
+// files have no entry at all.
+func parseTextDigest(s string) ([]RestoreEntry, map[int]string, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.SplitAfter(s, "\n")
+
+	isHeader := func(i int) bool {
+		return i+2 < len(lines) &&
+			strings.TrimSuffix(lines[i], "\n") == textDigestSeparator &&
+			strings.HasPrefix(lines[i+1], "File: ") &&
+			strings.TrimSuffix(lines[i+2], "\n") == textDigestSeparator
+	}
+
+	singleFileName := ""
+	if m := textSingleFileTreeLine.FindStringSubmatch(s); m != nil {
+		singleFileName = m[1]
+	}
+
+	var entries []RestoreEntry
+	duplicateOf := make(map[int]string)
+	for i := 0; i < len(lines); i++ {
+		if !isHeader(i) {
+			continue
+		}
+
+		header := strings.TrimSuffix(strings.TrimPrefix(lines[i+1], "File: "), "\n")
+		var body strings.Builder
+		j := i + 3
+		for ; j < len(lines) && !isHeader(j); j++ {
+			body.WriteString(lines[j])
+		}
+
+		entry := RestoreEntry{}
+		if m := textPlaceholderRegex.FindStringSubmatch(header); m != nil {
+			entry.Path = m[1]
+			entry.Placeholder = m[2]
+		} else if m := textDuplicateRegex.FindStringSubmatch(header); m != nil {
+			entry.Path = m[1]
+			duplicateOf[len(entries)] = m[2]
+		} else if strings.HasSuffix(header, textTruncatedSuffix) {
+			entry.Path = strings.TrimSuffix(header, textTruncatedSuffix)
+			entry.Placeholder = restorePlaceholderTruncated
+		} else {
+			entry.Path = header
+			entry.Content = strings.TrimSuffix(body.String(), "\n")
+		}
+		if entry.Path == "." && singleFileName != "" {
+			entry.Path = singleFileName
+		}
+		entries = append(entries, entry)
+		i = j - 1
+	}
+
+	if len(entries) == 0 {
+		return nil, nil, fmt.Errorf("no file entries found; expected a pathdigest text or JSON digest")
+	}
+	stripLineNumbers(entries)
+	return entries, duplicateOf, nil
+}
+
+// stripLineNumbers removes the "%*d | " prefixes written by numberLines when
+// the digest was made with --line-numbers. The prefixes are only removed when
+// every entry with content is numbered 1..n in that layout, so that a file
+// that merely looks numbered is not altered in a digest without them.
+func stripLineNumbers(entries []RestoreEntry) {
+	stripped := make([]string, len(entries))
+	numbered := false
+	for i, entry := range entries {
+		if entry.Placeholder != "" || entry.Content == "" {
+			continue
+		}
+		text, ok := unnumberLines(entry.Content)
+		if !ok {
+			return
+		}
+		stripped[i] = text
+		numbered = true
+	}
+	if !numbered {
+		return
+	}
+	for i := range entries {
+		if stripped[i] != "" {
+			entries[i].Content = stripped[i]
+		}
+	}
+}
+
+// unnumberLines reverses numberLines for a fully numbered file.
+func unnumberLines(content string) (string, bool) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	width := len(strconv.Itoa(len(lines)))
+	var sb strings.Builder
+	for i, line := range lines {
+		prefix := fmt.Sprintf("%*d | ", width, i+1)
+		if !strings.HasPrefix(line, prefix) {
+			return "", false
+		}
+		sb.WriteString(line[len(prefix):])
+		sb.WriteByte('\n')
+	}
+	return sb.String(), true
+}
+
+// RestoreEntries writes entries below targetDir. Paths that would resolve
+// outside targetDir, including through symlinks, are rejected before anything
+// is written.
+func RestoreEntries(entries []RestoreEntry, targetDir string, opts RestoreOptions) (*RestoreReport, error) {
+	absTarget, err := filepath.Abs(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for target directory: %w", err)
+	}
+
+	type plannedWrite struct {
+		entry RestoreEntry
+		path  string
+	}
+
+	report := &RestoreReport{}
+	var writes []plannedWrite
+	for _, entry := range entries {
+		if entry.Placeholder != "" {
+			report.Placeholders = append(report.Placeholders, entry)
+			continue
+		}
+		dest, err := safeRestorePath(absTarget, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(dest); err == nil && !opts.Overwrite {
+			report.Existing = append(report.Existing, entry)
+			continue
+		}
+		writes = append(writes, plannedWrite{entry: entry, path: dest})
+	}
+
+	for _, w := range writes {
+		if !opts.DryRun {
+			if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+				return report, fmt.Errorf("failed to create directory for %s: %w", w.entry.Path, err)
+			}
+			if err := os.WriteFile(w.path, []byte(w.entry.Content), 0644); err != nil {
+				return report, fmt.Errorf("failed to write %s: %w", w.entry.Path, err)
+			}
+		}
+		report.Written = append(report.Written, w.entry)
+	}
+	return report, nil
+}
+
+// safeRestorePath resolves a digest path below root and refuses absolute
+// paths, ".." escapes and existing symlinks that point outside root.
+func safeRestorePath(root, relPath string) (string, error) {
+	slashPath := filepath.ToSlash(relPath)
+	if relPath == "" || strings.HasPrefix(slashPath, "/") || filepath.IsAbs(relPath) || filepath.VolumeName(relPath) != "" {
+		return "", fmt.Errorf("refusing to restore %q: path must be relative", relPath)
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(slashPath))
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to restore %q: path escapes the target directory", relPath)
+	}
+	dest := filepath.Join(root, clean)
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nothing exists yet, so no symlink can redirect the write.
+			return dest, nil
+		}
+		return "", err
+	}
+
+	// Resolve the deepest existing ancestor and make sure it stays inside root.
+	existing := dest
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", existing, err)
+	}
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to restore %q: path resolves outside the target directory", relPath)
+	}
+	return dest, nil
+}
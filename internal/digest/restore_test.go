@@ -0,0 +1,205 @@
+package digest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func restoreTestResult() *Result {
+	root := &FileNode{
+		Name: "project",
+		Path: ".",
+		Type: NodeTypeDir,
+		Children: []*FileNode{
+			{
+				Name: "src", Path: "src", Type: NodeTypeDir, Depth: 1,
+				Children: []*FileNode{
+					{Name: "main.go", Path: "src/main.go", Type: NodeTypeFile, Size: 30, Depth: 2, Content: "package main\n\nfunc main() {}\n"},
+				},
+			},
+			{Name: "notes.md", Path: "notes.md", Type: NodeTypeFile, Size: 20, Depth: 1, Content: "# Notes\n\n" + fileSeparator + "not a header\n"},
+			{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 500, Depth: 1},
+			{Name: "dump.sql", Path: "dump.sql", Type: NodeTypeTruncated, Size: 5000, Depth: 1, Content: "a\n... [truncated] ...\nz\n"},
+		},
+	}
+	return &Result{RootNode: root, TotalFiles: 4}
+}
+
+func checkRestoreEntries(t *testing.T, entries []RestoreEntry) {
+	t.Helper()
+	want := map[string]RestoreEntry{
+		"src/main.go": {Path: "src/main.go", Content: "package main\n\nfunc main() {}\n"},
+		"notes.md":    {Path: "notes.md", Content: "# Notes\n\n" + fileSeparator + "not a header\n"},
+		"logo.png":    {Path: "logo.png", Placeholder: "non-text - content not included"},
+		"dump.sql":    {Path: "dump.sql", Placeholder: restorePlaceholderTruncated},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for _, e := range entries {
+		if w, ok := want[e.Path]; !ok || w != e {
+			t.Errorf("entry %+v, want %+v", e, w)
+		}
+	}
+}
+
+func TestParseDigest_Text(t *testing.T) {
+	r := restoreTestResult()
+	r.FormatOutput(IngestionOptions{Source: "."})
+	entries, err := ParseDigest([]byte(r.TreeStructure + "\n" + r.FileContents))
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	checkRestoreEntries(t, entries)
+}
+
+// Text digests lose the difference between files with and without a final
+// newline, and leave out empty files; JSON digests keep both.
+func TestParseDigest_TextIsNotByteExact(t *testing.T) {
+	r := &Result{RootNode: &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "empty.py", Path: "empty.py", Type: NodeTypeFile, Depth: 1},
+			{Name: "no-eol.txt", Path: "no-eol.txt", Type: NodeTypeFile, Size: 3, Depth: 1, Content: "abc"},
+		},
+	}, TotalFiles: 2}
+	opts := IngestionOptions{Source: "."}
+
+	r.FormatOutput(opts)
+	entries, err := ParseDigest([]byte(r.TreeStructure + "\n" + r.FileContents))
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	if len(entries) != 1 || entries[0] != (RestoreEntry{Path: "no-eol.txt", Content: "abc\n"}) {
+		t.Errorf("text entries = %+v, want only no-eol.txt with a newline added", entries)
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	entries, err = ParseDigest(data)
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	want := []RestoreEntry{{Path: "empty.py"}, {Path: "no-eol.txt", Content: "abc"}}
+	if len(entries) != len(want) || entries[0] != want[0] || entries[1] != want[1] {
+		t.Errorf("JSON entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestParseDigest_TextWithLineNumbers(t *testing.T) {
+	var long strings.Builder
+	for i := 0; i < 12; i++ {
+		long.WriteString("line\n")
+	}
+	r := &Result{RootNode: &FileNode{
+		Name: "project", Path: ".", Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "go.mod", Path: "go.mod", Type: NodeTypeFile, Depth: 1, Content: "module example.com/m\n\ngo 1.22\n"},
+			{Name: "long.txt", Path: "long.txt", Type: NodeTypeFile, Depth: 1, Content: long.String()},
+		},
+	}, TotalFiles: 2}
+
+	r.FormatOutput(IngestionOptions{Source: ".", LineNumbers: true})
+	entries, err := ParseDigest([]byte(r.TreeStructure + "\n" + r.FileContents))
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Content != "module example.com/m\n\ngo 1.22\n" || entries[1].Content != long.String() {
+		t.Errorf("entries = %+v, want line numbers stripped", entries)
+	}
+
+	// A file that only looks numbered is kept as is in a plain digest.
+	r.RootNode.Children[1].Content = "plain\n"
+	r.RootNode.Children[0].Content = "1 | not a prefix\n"
+	r.FormatOutput(IngestionOptions{Source: "."})
+	entries, err = ParseDigest([]byte(r.TreeStructure + "\n" + r.FileContents))
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	if entries[0].Content != "1 | not a prefix\n" {
+		t.Errorf("go.mod content = %q, want it unchanged", entries[0].Content)
+	}
+}
+
+func TestParseDigest_JSON(t *testing.T) {
+	data, err := restoreTestResult().FormatJSON(IngestionOptions{Source: "."})
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	entries, err := ParseDigest(data)
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	checkRestoreEntries(t, entries)
+}
+
+func TestParseDigest_SingleFile(t *testing.T) {
+	r := &Result{RootNode: &FileNode{Name: "script.sh", Path: ".", Type: NodeTypeFile, Content: "echo hi\n"}, TotalFiles: 1}
+	r.FormatOutput(IngestionOptions{Source: "script.sh"})
+	entries, err := ParseDigest([]byte(r.TreeStructure + "\n" + r.FileContents))
+	if err != nil {
+		t.Fatalf("ParseDigest returned error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "script.sh" || entries[0].Content != "echo hi\n" {
+		t.Errorf("entries = %+v, want script.sh", entries)
+	}
+}
+
+func TestRestoreEntries(t *testing.T) {
+	target := t.TempDir()
+	entries := []RestoreEntry{
+		{Path: "a/b.txt", Content: "hello\n"},
+		{Path: "img.png", Placeholder: "non-text - content not included"},
+	}
+
+	report, err := RestoreEntries(entries, target, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	if len(report.Written) != 1 || len(report.Placeholders) != 1 {
+		t.Errorf("dry run report = %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(target, "a")); !os.IsNotExist(err) {
+		t.Error("dry run wrote to disk")
+	}
+
+	if _, err := RestoreEntries(entries, target, RestoreOptions{}); err != nil {
+		t.Fatalf("RestoreEntries returned error: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(target, "a", "b.txt"))
+	if err != nil || string(got) != "hello\n" {
+		t.Errorf("restored content = %q, %v", got, err)
+	}
+
+	report, err = RestoreEntries(entries, target, RestoreOptions{})
+	if err != nil || len(report.Existing) != 1 || len(report.Written) != 0 {
+		t.Errorf("second restore report = %+v, err = %v; want existing file skipped", report, err)
+	}
+}
+
+func TestRestoreEntries_RefusesEscapes(t *testing.T) {
+	target := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(target, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	for _, path := range []string{"../evil.txt", "a/../../evil.txt", "/etc/evil", "link/evil.txt"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := RestoreEntries([]RestoreEntry{{Path: "ok.txt", Content: "x"}, {Path: path, Content: "x"}}, target, RestoreOptions{})
+			if err == nil || !strings.Contains(err.Error(), "refusing") {
+				t.Fatalf("RestoreEntries(%q) error = %v, want refusal", path, err)
+			}
+			if _, err := os.Stat(filepath.Join(target, "ok.txt")); !os.IsNotExist(err) {
+				t.Error("files were written before the unsafe path was rejected")
+			}
+		})
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("files were written outside the target: %v", entries)
+	}
+}
@@ -0,0 +1,30 @@
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ga1az/pathdigest/internal/encutil"
+)
+
+// JSONOutputSchema returns the JSON Schema of the JSON output, generated from
+// the JSONOutput types and pinned to JSONSchemaVersion.
+func JSONOutputSchema() *encutil.Schema {
+	s := encutil.GenerateSchema(JSONOutput{})
+	s.ID = fmt.Sprintf("urn:pathdigest:schema:digest:v%d", JSONSchemaVersion)
+	s.Title = "pathdigest JSON digest"
+	s.Description = fmt.Sprintf("Output of pathdigest --format json, schema version %d.", JSONSchemaVersion)
+	s.Properties["schema_version"].Const = JSONSchemaVersion
+	return s
+}
+
+// FormatJSONSchema renders JSONOutputSchema as indented JSON.
+func FormatJSONSchema() ([]byte, error) {
+	return json.MarshalIndent(JSONOutputSchema(), "", "  ")
+}
+
+// ValidateJSONDigest checks a JSON digest against JSONOutputSchema. It
+// returns an error only when data is not JSON at all.
+func ValidateJSONDigest(data []byte) ([]encutil.ValidationError, error) {
+	return encutil.Validate(JSONOutputSchema(), data)
+}
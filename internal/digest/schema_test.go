@@ -0,0 +1,135 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ga1az/pathdigest/internal/gitutil"
+)
+
+func assertValidJSONDigest(t *testing.T, r *Result, opts IngestionOptions) {
+	t.Helper()
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	violations, err := ValidateJSONDigest(data)
+	if err != nil {
+		t.Fatalf("ValidateJSONDigest returned error: %v", err)
+	}
+	for _, v := range violations {
+		t.Errorf("schema violation: %v", v)
+	}
+	if t.Failed() {
+		t.Logf("JSON:\n%s", data)
+	}
+}
+
+func TestJSONOutputValidatesAgainstSchema(t *testing.T) {
+	profile := DefaultModelProfiles[0]
+	full := &Result{
+		RootNode: &FileNode{
+			Name: "repo", Path: ".", Type: NodeTypeDir,
+			Children: []*FileNode{
+				{Name: "src", Path: "src", Type: NodeTypeDir, Children: []*FileNode{
+					{Name: "main.go", Path: "src/main.go", Type: NodeTypeFile, Content: "package main\n", Mode: 0755, ModTime: time.Now(), SHA256: "abc"},
+				}},
+				{Name: "big.log", Path: "big.log", Type: NodeTypeTruncated, Size: 4096, Content: "a\n... [truncated] ...\nz\n",
+					Truncation: &Truncation{HeadLines: 1, TailLines: 1, OmittedLines: 10, OmittedBytes: 4000}},
+				{Name: "logo.png", Path: "logo.png", Type: NodeTypeNotText, Size: 10, Error: errors.New("boom")},
+				{Name: "huge.bin", Path: "huge.bin", Type: NodeTypeTooLarge, Size: 1 << 30},
+				{Name: "link", Path: "link", Type: NodeTypeSymlink},
+				{Name: "empty.txt", Path: "empty.txt", Type: NodeTypeFile},
+			},
+		},
+		TotalFiles: 5,
+		GitInfo:    &gitutil.GitURLParts{RepoURL: "https://example.com/u/r", Branch: "main", User: "u", RepoName: "r"},
+	}
+
+	tests := []struct {
+		name string
+		r    *Result
+		opts IngestionOptions
+	}{
+		{"empty directory", &Result{RootNode: &FileNode{Name: "empty", Path: ".", Type: NodeTypeDir}}, IngestionOptions{Source: "."}},
+		{"single file", &Result{RootNode: &FileNode{Name: "a.go", Path: ".", Type: NodeTypeFile, Content: "package a\n"}, TotalFiles: 1}, IngestionOptions{Source: "a.go"}},
+		{"full", full, IngestionOptions{Source: ".", ExcludePatterns: []string{".git/"}, IncludePatterns: []string{"*.go"}}},
+		{"full with options", full, IngestionOptions{Source: ".", LineNumbers: true, Model: &profile, Order: OrderSize}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValidJSONDigest(t, tt.r, tt.opts)
+		})
+	}
+}
+
+func TestIngestedJSONValidatesAgainstSchema(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"README.md":       "# Project\n",
+		"cmd/main.go":     "package main\n\nfunc main() {}\n",
+		"scripts/run.sh":  "#!/bin/sh\necho hi\n",
+		"data/blob.bin":   "\x00\x01\x02",
+		"logs/big.log":    strings.Repeat("line\n", 500),
+		"notes/empty.txt": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := IngestionOptions{
+		Source:        dir,
+		MaxFileSize:   1024,
+		TruncateLarge: TruncateSpec{HeadLines: 5, TailLines: 5},
+		LineNumbers:   true,
+	}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	assertValidJSONDigest(t, r, opts)
+}
+
+func TestValidateJSONDigest_RejectsDrift(t *testing.T) {
+	violations, err := ValidateJSONDigest([]byte(`{"schema_version":1,"summary":{},"tree":[],"files":[]}`))
+	if err != nil {
+		t.Fatalf("ValidateJSONDigest returned error: %v", err)
+	}
+	if len(violations) == 0 {
+		t.Error("digest with missing fields was accepted")
+	}
+}
+
+func TestValidateJSONDigest_AllowsAddedFields(t *testing.T) {
+	r := &Result{RootNode: &FileNode{Name: "p", Path: ".", Type: NodeTypeDir}}
+	data, err := r.FormatJSON(IngestionOptions{Source: "."})
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc["added_later"] = true
+	doc["summary"].(map[string]any)["added_later"] = 1
+	data, _ = json.Marshal(doc)
+
+	violations, err := ValidateJSONDigest(data)
+	if err != nil {
+		t.Fatalf("ValidateJSONDigest returned error: %v", err)
+	}
+	if len(violations) > 0 {
+		t.Errorf("fields added by a later version were rejected: %v", violations)
+	}
+}
@@ -0,0 +1,126 @@
+package digest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/redact"
+)
+
+const (
+	SecretsOff    = "off"
+	SecretsWarn   = "warn"
+	SecretsRedact = "redact"
+	SecretsFail   = "fail"
+)
+
+// SecretsModes lists the accepted values for IngestionOptions.Secrets. An
+// empty mode is the same as SecretsOff.
+var SecretsModes = []string{SecretsOff, SecretsWarn, SecretsRedact, SecretsFail}
+
+func IsValidSecretsMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	for _, m := range SecretsModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretFinding is a secret found in an ingested file.
+type SecretFinding struct {
+	Path string
+	redact.Finding
+}
+
+// SecretsError is returned by ProcessSource in SecretsFail mode when any
+// ingested file contains a secret.
+type SecretsError struct {
+	Findings []SecretFinding
+}
+
+func (e *SecretsError) Error() string {
+	return fmt.Sprintf("found %d secret(s) in %d file(s)", len(e.Findings), secretFileCount(e.Findings))
+}
+
+func secretFileCount(findings []SecretFinding) int {
+	files := make(map[string]bool)
+	for _, f := range findings {
+		files[f.Path] = true
+	}
+	return len(files)
+}
+
+// SecretsAllowlist suppresses known false positives of the secret scanner.
+type SecretsAllowlist = redact.Allowlist
+
+// LoadSecretsAllowlist reads an allowlist file for
+// IngestionOptions.SecretsAllowlist.
+func LoadSecretsAllowlist(path string) (*redact.Allowlist, error) {
+	return redact.LoadAllowlist(path)
+}
+
+// scanSecrets records the secrets in node's content and, in SecretsRedact
+// mode, replaces them. It runs before content transforms, so that stripping
+// comments cannot hide a secret from the report.
+func scanSecrets(node *FileNode, opts IngestionOptions) {
+	if opts.Secrets == "" || opts.Secrets == SecretsOff || node.Content == "" {
+		return
+	}
+	if allow := opts.SecretsAllowlist; allow != nil && len(allow.Paths) > 0 &&
+		patternRank(node.Path, allow.Paths) < len(allow.Paths) {
+		return
+	}
+	node.Secrets = redact.Scan(node.Content, opts.SecretsAllowlist)
+	if opts.Secrets == SecretsRedact && len(node.Secrets) > 0 {
+		node.Content = redact.Redact(node.Content, node.Secrets)
+	}
+}
+
+// SecretFindings returns the secrets found during ingestion in tree order.
+func (r *Result) SecretFindings() []SecretFinding {
+	var findings []SecretFinding
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		path := filepath.ToSlash(node.Path)
+		if node == r.RootNode {
+			path = node.Name
+		}
+		for _, f := range node.Secrets {
+			findings = append(findings, SecretFinding{Path: path, Finding: f})
+		}
+		return true
+	})
+	return findings
+}
+
+// FormatSecretsReport lists findings one per line with their fingerprints,
+// which can be copied into an allowlist file.
+func FormatSecretsReport(findings []SecretFinding, mode string) string {
+	action := map[string]string{
+		SecretsWarn:   "included",
+		SecretsRedact: "redacted",
+		SecretsFail:   "blocked",
+	}[mode]
+
+	var sb strings.Builder
+	for _, f := range findings {
+		sb.WriteString(fmt.Sprintf("%s:%d: %s (sha256:%s) %s\n", f.Path, f.Line, f.RuleID, f.Fingerprint, action))
+	}
+	return sb.String()
+}
+
+func (r *Result) secretsSummaryRows(opts IngestionOptions) []summaryRow {
+	findings := r.SecretFindings()
+	if len(findings) == 0 {
+		return nil
+	}
+	value := fmt.Sprintf("%d secret(s) in %d file(s)", len(findings), secretFileCount(findings))
+	if opts.Secrets == SecretsRedact {
+		value += ", redacted"
+	}
+	return []summaryRow{{"Secrets", value}}
+}
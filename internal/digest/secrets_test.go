@@ -0,0 +1,108 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ga1az/pathdigest/internal/redact"
+)
+
+// testAWSKeyID is assembled at run time so that the source itself does not
+// trip secret scanners.
+var testAWSKeyID = "AKIA" + "IOSFODNN7EXAMPLE"
+
+func secretsTestTree(t *testing.T) string {
+	return writeTestTree(t, map[string]string{
+		"config.go":         "package config\n\nconst key = \"" + testAWSKeyID + "\"\n",
+		"fixtures/creds.go": "package fixtures\n\nconst key = \"" + testAWSKeyID + "\"\n",
+		"main.go":           "package main\n",
+	})
+}
+
+func TestScanSecrets_Redact(t *testing.T) {
+	allow := &redact.Allowlist{Paths: []string{"fixtures/"}}
+	opts := IngestionOptions{Source: secretsTestTree(t), MaxFileSize: 1024, Secrets: SecretsRedact, SecretsAllowlist: allow}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+
+	findings := r.SecretFindings()
+	if len(findings) != 1 || findings[0].Path != "config.go" || findings[0].Line != 3 {
+		t.Fatalf("findings = %+v, want one in config.go", findings)
+	}
+
+	r.FormatOutput(opts)
+	if strings.Count(r.FileContents, testAWSKeyID) != 1 {
+		t.Errorf("expected the key to remain only in the allowlisted file:\n%s", r.FileContents)
+	}
+	if !strings.Contains(r.FileContents, `const key = "[REDACTED:aws-access-key-id]"`) {
+		t.Errorf("key was not redacted:\n%s", r.FileContents)
+	}
+	if !strings.Contains(r.Summary, "Secrets: 1 secret(s) in 1 file(s), redacted") {
+		t.Errorf("summary does not report the finding:\n%s", r.Summary)
+	}
+
+	report := FormatSecretsReport(findings, SecretsRedact)
+	if want := "config.go:3: aws-access-key-id (sha256:" + redact.Fingerprint(testAWSKeyID) + ") redacted\n"; report != want {
+		t.Errorf("report = %q, want %q", report, want)
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	if strings.Count(string(data), testAWSKeyID) != 1 {
+		t.Error("JSON output leaks the redacted key")
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Summary.SecretFindings != 1 {
+		t.Errorf("secret_findings = %d, want 1", out.Summary.SecretFindings)
+	}
+	assertValidJSONDigest(t, r, opts)
+}
+
+func TestScanSecrets_Warn(t *testing.T) {
+	opts := IngestionOptions{Source: secretsTestTree(t), MaxFileSize: 1024, Secrets: SecretsWarn}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	if n := len(r.SecretFindings()); n != 2 {
+		t.Errorf("got %d findings, want 2", n)
+	}
+	r.FormatOutput(opts)
+	if strings.Count(r.FileContents, testAWSKeyID) != 2 {
+		t.Error("warn mode changed the content")
+	}
+}
+
+func TestScanSecrets_Fail(t *testing.T) {
+	opts := IngestionOptions{Source: secretsTestTree(t), MaxFileSize: 1024, Secrets: SecretsFail}
+	r, err := ProcessSource(opts)
+	var secretsErr *SecretsError
+	if !errors.As(err, &secretsErr) {
+		t.Fatalf("ProcessSource error = %v, want *SecretsError", err)
+	}
+	if r != nil || len(secretsErr.Findings) != 2 {
+		t.Errorf("result = %v, findings = %+v", r, secretsErr.Findings)
+	}
+	if err.Error() != "found 2 secret(s) in 2 file(s)" {
+		t.Errorf("error = %q", err.Error())
+	}
+}
+
+func TestScanSecrets_OffByDefault(t *testing.T) {
+	r, err := ProcessSource(IngestionOptions{Source: secretsTestTree(t), MaxFileSize: 1024})
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	if n := len(r.SecretFindings()); n != 0 {
+		t.Errorf("got %d findings with scanning off", n)
+	}
+}
@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+)
+
+// TemplateData is the value passed to user templates.
+type TemplateData struct {
+	Result  *Result
+	Options IngestionOptions
+	// Root is the root of the file tree.
+	Root *FileNode
+	// Files lists the content-bearing nodes in content order.
+	Files []*FileNode
+	// Summary and Tree hold the text renderings used by the default format.
+	Summary string
+	Tree    string
+}
+
+// TemplateFuncs are the helper functions available to user templates.
+var TemplateFuncs = template.FuncMap{
+	"language": langutil.Detect,
+	"tokens":   estimateTokens,
+	"indent":   indentLines,
+	"fence":    markdownFence,
+	"bytes":    formatBytes,
+	"slash":    filepath.ToSlash,
+}
+
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter parses a text/template file and returns a Formatter
+// that renders TemplateData through it.
+func NewTemplateFormatter(path string) (Formatter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	return &templateFormatter{tmpl: tmpl}, nil
+}
+
+func (f *templateFormatter) Name() string { return "template" }
+
+func (f *templateFormatter) Format(w io.Writer, r *Result, opts IngestionOptions) error {
+	var sbTree strings.Builder
+	r.writeTree(&sbTree)
+
+	data := TemplateData{
+		Result:  r,
+		Options: opts,
+		Root:    r.RootNode,
+		Files:   r.contentNodes(opts),
+		Summary: formatSummaryRows(r.summaryRows(opts)),
+		Tree:    sbTree.String(),
+	}
+	return f.tmpl.Execute(w, data)
+}
+
+// indentLines prefixes every non-empty line of s with n spaces.
+func indentLines(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.SplitAfter(s, "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		if line != "" && line != "\n" {
+			sb.WriteString(pad)
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
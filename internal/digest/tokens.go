@@ -0,0 +1,61 @@
+package digest
+
+import "unicode/utf8"
+
+const (
+	charsPerToken = 4
+)
+
+// estimateTokens approximates the token count of s with the common heuristic
+// of about four characters per token. It is deliberately tokenizer-agnostic.
+func estimateTokens(s string) int {
+	if s == "" {
+		return 0
+	}
+	return (utf8.RuneCountInString(s) + charsPerToken - 1) / charsPerToken
+}
+
+// countTokens sums the estimated tokens of all content below node. Files
+// marked as duplicates count nothing, as the digest leaves their content out.
+func countTokens(node *FileNode) int {
+	if node == nil {
+		return 0
+	}
+	total := 0
+	if node.DuplicateOf == "" {
+		total = estimateTokens(node.Content)
+	}
+	for _, child := range node.Children {
+		total += countTokens(child)
+	}
+	return total
+}
+
+// tokenCounter is an io.Writer that discards what is written to it and
+// estimates its tokens like estimateTokens.
+type tokenCounter struct {
+	runes int
+}
+
+func (c *tokenCounter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if !utf8.RuneStart(b) {
+			continue
+		}
+		c.runes++
+	}
+	return len(p), nil
+}
+
+func (c *tokenCounter) tokens() int {
+	return (c.runes + charsPerToken - 1) / charsPerToken
+}
+
+// fitTokens returns the token count a model fit is estimated from: the
+// rendered output when Render measured it, otherwise the file content.
+func (r *Result) fitTokens() int {
+	if r.OutputTokens > 0 {
+		return r.OutputTokens
+	}
+	return r.TokenCount
+}
@@ -0,0 +1,164 @@
+package digest
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+	"github.com/ga1az/pathdigest/internal/outline"
+)
+
+const (
+	TransformLicenseHeaders = "license-headers"
+	TransformOutline        = "outline"
+	TransformStripComments  = "strip-comments"
+	TransformCompact        = "compact"
+)
+
+// TransformStat records how one content transform changed a file.
+type TransformStat struct {
+	Name         string
+	TokensBefore int
+	TokensAfter  int
+}
+
+// contentTransform rewrites the content of a text file during ingestion.
+// apply reports false when the transform does not apply to the file, for
+// example because its language is not supported.
+type contentTransform struct {
+	name  string
+	apply func(node *FileNode, content string, opts IngestionOptions) (string, bool)
+}
+
+var transformLabels = map[string]string{
+	TransformLicenseHeaders: "License headers stripped",
+	TransformOutline:        "Outlined",
+	TransformStripComments:  "Comments stripped",
+	TransformCompact:        "Compacted",
+}
+
+// contentTransforms returns the transforms enabled by opts in the order they
+// run.
+func contentTransforms(opts IngestionOptions) []contentTransform {
+	var transforms []contentTransform
+	if opts.StripLicenseHeaders {
+		transforms = append(transforms, contentTransform{name: TransformLicenseHeaders, apply: licenseHeaderTransform})
+	}
+	if len(opts.Outline) > 0 {
+		transforms = append(transforms, contentTransform{name: TransformOutline, apply: outlineTransform})
+	}
+	if opts.StripComments {
+		transforms = append(transforms, contentTransform{name: TransformStripComments, apply: stripCommentsTransform})
+	}
+	if opts.Compact {
+		transforms = append(transforms, contentTransform{name: TransformCompact, apply: compactTransform})
+	}
+	return transforms
+}
+
+// applyContentTransforms runs the enabled transforms over a fully read text
+// file and records their effect on the node.
+func applyContentTransforms(node *FileNode, opts IngestionOptions) {
+	for _, t := range contentTransforms(opts) {
+		before := node.Content
+		after, ok := t.apply(node, before, opts)
+		if !ok {
+			continue
+		}
+		node.Content = after
+		node.Transforms = append(node.Transforms, TransformStat{
+			Name:         t.name,
+			TokensBefore: estimateTokens(before),
+			TokensAfter:  estimateTokens(after),
+		})
+	}
+}
+
+func stripCommentsTransform(node *FileNode, content string, opts IngestionOptions) (string, bool) {
+	return langutil.StripComments(langutil.Detect(node.Path), content, opts.KeepDocComments)
+}
+
+// licenseHeaderTransform removes a file's license header and keeps its text
+// on the node, so that the summary can list it once.
+func licenseHeaderTransform(node *FileNode, content string, opts IngestionOptions) (string, bool) {
+	out, header, ok := langutil.StripLicenseHeader(langutil.Detect(node.Path), content, opts.LicenseTemplate)
+	if !ok {
+		return content, false
+	}
+	node.LicenseHeader = header
+	return out, true
+}
+
+func compactTransform(node *FileNode, content string, opts IngestionOptions) (string, bool) {
+	return langutil.Compact(langutil.Detect(node.Path), content, opts.CompactTabWidth), true
+}
+
+// OutlineLanguages lists the accepted values for IngestionOptions.Outline.
+func OutlineLanguages() []string {
+	return outline.Languages()
+}
+
+func IsValidOutlineLanguage(lang string) bool {
+	return outline.Supports(lang)
+}
+
+// outlineTransform outlines files in one of opts.Outline unless they match
+// opts.OutlineKeep. Files that fail to parse keep their full content.
+func outlineTransform(node *FileNode, content string, opts IngestionOptions) (string, bool) {
+	lang := langutil.Detect(node.Path)
+	if !slices.Contains(opts.Outline, lang) || patternRank(node.Path, opts.OutlineKeep) < len(opts.OutlineKeep) {
+		return content, false
+	}
+	return outline.Outline(lang, content)
+}
+
+// transformTotal aggregates one transform's effect across all files.
+type transformTotal struct {
+	Name         string
+	Files        int
+	TokensBefore int
+	TokensAfter  int
+}
+
+func (t transformTotal) saved() int {
+	return t.TokensBefore - t.TokensAfter
+}
+
+// transformTotals sums the TransformStats of all nodes, sorted by name.
+func (r *Result) transformTotals() []transformTotal {
+	byName := make(map[string]*transformTotal)
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		for _, stat := range node.Transforms {
+			total, ok := byName[stat.Name]
+			if !ok {
+				total = &transformTotal{Name: stat.Name}
+				byName[stat.Name] = total
+			}
+			total.Files++
+			total.TokensBefore += stat.TokensBefore
+			total.TokensAfter += stat.TokensAfter
+		}
+		return true
+	})
+
+	totals := make([]transformTotal, 0, len(byName))
+	for _, total := range byName {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Name < totals[j].Name })
+	return totals
+}
+
+func (r *Result) transformSummaryRows() []summaryRow {
+	var rows []summaryRow
+	for _, total := range r.transformTotals() {
+		label := transformLabels[total.Name]
+		if label == "" {
+			label = total.Name
+		}
+		rows = append(rows, summaryRow{label, fmt.Sprintf("%d files, %d tokens saved (%d -> %d)",
+			total.Files, total.saved(), total.TokensBefore, total.TokensAfter)})
+	}
+	return rows
+}
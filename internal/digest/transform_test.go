@@ -0,0 +1,217 @@
+package digest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestTree creates files, keyed by slash-separated relative path, under
+// a new temporary directory and returns it.
+func writeTestTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestStripCommentsTransform(t *testing.T) {
+	goSrc := "package main\n\n// main runs.\nfunc main() {\n\t// say hi\n\tprintln(\"// hi\")\n}\n"
+	dir := writeTestTree(t, map[string]string{
+		"main.go":   goSrc,
+		"notes.txt": "# not code\n",
+	})
+
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024, StripComments: true}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+
+	if strings.Contains(r.FileContents, "say hi") || strings.Contains(r.FileContents, "main runs") {
+		t.Errorf("comments were not stripped:\n%s", r.FileContents)
+	}
+	if !strings.Contains(r.FileContents, `println("// hi")`) || !strings.Contains(r.FileContents, "# not code") {
+		t.Errorf("string literal or unsupported file was changed:\n%s", r.FileContents)
+	}
+	if !strings.Contains(r.Summary, "Strip Comments: enabled") ||
+		!strings.Contains(r.Summary, "Comments stripped: 1 files, ") {
+		t.Errorf("summary does not report stripping:\n%s", r.Summary)
+	}
+
+	var main *FileNode
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		if node.Name == "main.go" {
+			main = node
+		}
+		return true
+	})
+	if main == nil || len(main.Transforms) != 1 {
+		t.Fatalf("main.go transforms = %+v, want one entry", main)
+	}
+	if stat := main.Transforms[0]; stat.TokensBefore <= stat.TokensAfter {
+		t.Errorf("stat = %+v, want fewer tokens after stripping", stat)
+	}
+	if main.SHA256 != contentSHA256(main.Content) || main.SHA256 == contentSHA256(goSrc) {
+		t.Error("SHA256 does not describe the transformed content")
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Summary.Transforms) != 1 || out.Summary.Transforms[0].Files != 1 {
+		t.Errorf("JSON summary transforms = %+v", out.Summary.Transforms)
+	}
+	assertValidJSONDigest(t, r, opts)
+}
+
+func TestStripCommentsTransform_KeepDocComments(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"main.go": "package main\n\n// main runs.\nfunc main() {\n\t// say hi\n}\n",
+	})
+
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024, StripComments: true, KeepDocComments: true}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+
+	if !strings.Contains(r.FileContents, "// main runs.") || strings.Contains(r.FileContents, "say hi") {
+		t.Errorf("unexpected content:\n%s", r.FileContents)
+	}
+	if !strings.Contains(r.Summary, "Strip Comments: enabled, keeping doc comments") {
+		t.Errorf("summary does not mention doc comments:\n%s", r.Summary)
+	}
+}
+
+func TestOutlineTransform(t *testing.T) {
+	body := "package app\n\n// Run starts the app.\nfunc Run() error {\n\treturn nil\n}\n"
+	dir := writeTestTree(t, map[string]string{
+		"app.go":          body,
+		"focus/focus.go":  body,
+		"broken/wip.go":   "package wip\n\nfunc {\n",
+		"scripts/main.py": "def main():\n    pass\n",
+	})
+
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024, Outline: []string{"go"}, OutlineKeep: []string{"focus/"}}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+
+	contents := make(map[string]string)
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		if node.Type == NodeTypeFile {
+			contents[filepath.ToSlash(node.Path)] = node.Content
+		}
+		return true
+	})
+	if want := "package app\n\n// Run starts the app.\nfunc Run() error { ... }\n"; contents["app.go"] != want {
+		t.Errorf("app.go = %q, want %q", contents["app.go"], want)
+	}
+	if contents["focus/focus.go"] != body {
+		t.Errorf("focus/focus.go was outlined despite --outline-keep: %q", contents["focus/focus.go"])
+	}
+	if contents["broken/wip.go"] != "package wip\n\nfunc {\n" {
+		t.Errorf("unparsable file was changed: %q", contents["broken/wip.go"])
+	}
+	if contents["scripts/main.py"] != "def main():\n    pass\n" {
+		t.Errorf("Python file was outlined: %q", contents["scripts/main.py"])
+	}
+
+	r.FormatOutput(opts)
+	if !strings.Contains(r.Summary, "Outline: go") || !strings.Contains(r.Summary, "Outlined: 1 files, ") {
+		t.Errorf("summary does not report the outline:\n%s", r.Summary)
+	}
+}
+
+func TestCompactTransform(t *testing.T) {
+	dir := writeTestTree(t, map[string]string{
+		"main.go": "package main\r\n\r\n\r\n\r\nfunc main() {  \r\n        println()\r\n}\r\n",
+		"tool.py": "def f():\n    return 1   \n\n\n\nf()\n",
+	})
+
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024, Compact: true, CompactTabWidth: 4}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+
+	contents := make(map[string]string)
+	walkNodes(r.RootNode, func(node *FileNode) bool {
+		contents[node.Name] = node.Content
+		return true
+	})
+	if want := "package main\n\nfunc main() {\n\t\tprintln()\n}\n"; contents["main.go"] != want {
+		t.Errorf("main.go = %q, want %q", contents["main.go"], want)
+	}
+	if want := "def f():\n    return 1\n\nf()\n"; contents["tool.py"] != want {
+		t.Errorf("tool.py = %q, want %q", contents["tool.py"], want)
+	}
+
+	r.FormatOutput(opts)
+	if !strings.Contains(r.Summary, "Compact: enabled, tabs of 4 columns") ||
+		!strings.Contains(r.Summary, "Compacted: 2 files, ") {
+		t.Errorf("summary does not report compaction:\n%s", r.Summary)
+	}
+}
+
+func TestLicenseHeaderTransform(t *testing.T) {
+	const header = "// Copyright 2024 Acme Corp.\n// SPDX-License-Identifier: Apache-2.0\n\n"
+	dir := writeTestTree(t, map[string]string{
+		"a.go":     header + "package a\n",
+		"b/b.go":   header + "package b\n",
+		"c.py":     "# Internal use only.\nimport os\n",
+		"plain.go": "package plain\n",
+	})
+
+	opts := IngestionOptions{Source: dir, MaxFileSize: 1024, StripLicenseHeaders: true, LicenseTemplate: "# Internal use only.\n"}
+	r, err := ProcessSource(opts)
+	if err != nil {
+		t.Fatalf("ProcessSource returned error: %v", err)
+	}
+	r.FormatOutput(opts)
+
+	if strings.Contains(r.FileContents, "Copyright") || strings.Contains(r.FileContents, "Internal use only") {
+		t.Errorf("license headers were not stripped:\n%s", r.FileContents)
+	}
+	for _, want := range []string{
+		"Strip License Headers: enabled, with template",
+		"License headers stripped: 3 files, ",
+		"License header: Copyright 2024 Acme Corp. SPDX-License-Identifier: Apache-2.0 (2 files)",
+		"License header: Internal use only. (1 files)",
+	} {
+		if !strings.Contains(r.Summary, want) {
+			t.Errorf("expected %q in summary:\n%s", want, r.Summary)
+		}
+	}
+
+	data, err := r.FormatJSON(opts)
+	if err != nil {
+		t.Fatalf("FormatJSON returned error: %v", err)
+	}
+	var out JSONOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.Summary.LicenseHeaders) != 2 || out.Summary.LicenseHeaders[0].Files != 2 {
+		t.Errorf("license_headers = %+v", out.Summary.LicenseHeaders)
+	}
+}
@@ -0,0 +1,103 @@
+package digest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/fsutil"
+)
+
+const (
+	truncationMarkerFormat = "... [truncated: %d lines (%s) omitted] ..."
+)
+
+// TruncateSpec controls how much of an oversized file is kept instead of
+// dropping its content entirely.
+type TruncateSpec struct {
+	HeadLines int
+	TailLines int
+}
+
+func (s TruncateSpec) Enabled() bool {
+	return s.HeadLines > 0 || s.TailLines > 0
+}
+
+func (s TruncateSpec) String() string {
+	var parts []string
+	if s.HeadLines > 0 {
+		parts = append(parts, fmt.Sprintf("head:%d", s.HeadLines))
+	}
+	if s.TailLines > 0 {
+		parts = append(parts, fmt.Sprintf("tail:%d", s.TailLines))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseTruncateSpec parses values like "head:200,tail:50", "head:100" or "tail:20".
+func ParseTruncateSpec(value string) (TruncateSpec, error) {
+	var spec TruncateSpec
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return spec, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		key, num, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return spec, fmt.Errorf("invalid truncate spec %q: expected head:N or tail:M", part)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(num))
+		if err != nil || n < 0 {
+			return spec, fmt.Errorf("invalid line count %q in truncate spec", num)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "head":
+			spec.HeadLines = n
+		case "tail":
+			spec.TailLines = n
+		default:
+			return spec, fmt.Errorf("invalid truncate spec key %q: expected head or tail", key)
+		}
+	}
+	return spec, nil
+}
+
+// truncateFileNode keeps the head and tail of an oversized text file. Non-text
+// files and read errors leave the node as NodeTypeTooLarge.
+func truncateFileNode(node *FileNode, opts IngestionOptions) {
+	isText, err := fsutil.IsTextFile(node.FullPath)
+	if err != nil || !isText {
+		return
+	}
+
+	ht, err := fsutil.ReadHeadTail(node.FullPath, opts.TruncateLarge.HeadLines, opts.TruncateLarge.TailLines, opts.MaxFileSize)
+	if err != nil {
+		node.Error = fmt.Errorf("error reading truncated file content: %w", err)
+		return
+	}
+
+	if ht.OmittedBytes == 0 {
+		node.Type = NodeTypeFile
+		node.Content = ht.Head + ht.Tail
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(ht.Head)
+	if ht.Head != "" && !strings.HasSuffix(ht.Head, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf(truncationMarkerFormat, ht.OmittedLines, formatBytes(ht.OmittedBytes)))
+	sb.WriteString("\n")
+	sb.WriteString(ht.Tail)
+
+	node.Type = NodeTypeTruncated
+	node.Content = sb.String()
+	node.Truncation = &Truncation{
+		HeadLines:    ht.HeadLines,
+		TailLines:    ht.TailLines,
+		OmittedLines: ht.OmittedLines,
+		OmittedBytes: ht.OmittedBytes,
+	}
+}
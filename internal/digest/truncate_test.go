@@ -0,0 +1,68 @@
+package digest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTruncateSpec(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TruncateSpec
+		wantErr bool
+	}{
+		{"", TruncateSpec{}, false},
+		{"head:200,tail:50", TruncateSpec{HeadLines: 200, TailLines: 50}, false},
+		{"head:10", TruncateSpec{HeadLines: 10}, false},
+		{" tail:5 ", TruncateSpec{TailLines: 5}, false},
+		{"head", TruncateSpec{}, true},
+		{"middle:4", TruncateSpec{}, true},
+		{"head:-1", TruncateSpec{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTruncateSpec(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTruncateSpec(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseTruncateSpec(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFileNode_Truncates(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "dump.sql")
+	content := strings.Repeat("INSERT INTO t VALUES (1);\n", 1000)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file %q: %v", path, err)
+	}
+
+	node := &FileNode{Name: "dump.sql", Path: "dump.sql", FullPath: path, Type: NodeTypeFile, Size: int64(len(content))}
+	opts := IngestionOptions{MaxFileSize: 1024, TruncateLarge: TruncateSpec{HeadLines: 3, TailLines: 2}}
+	readFileNode(node, opts)
+
+	if node.Type != NodeTypeTruncated {
+		t.Fatalf("Type = %q, want %q", node.Type, NodeTypeTruncated)
+	}
+	if node.Truncation == nil || node.Truncation.OmittedLines != 995 {
+		t.Fatalf("Truncation = %+v, want 995 omitted lines", node.Truncation)
+	}
+	if !strings.Contains(node.Content, "[truncated: 995 lines") {
+		t.Errorf("Content is missing elision marker:\n%s", node.Content)
+	}
+	if got := strings.Count(node.Content, "INSERT"); got != 5 {
+		t.Errorf("Content has %d kept lines, want 5", got)
+	}
+
+	node = &FileNode{Name: "dump.sql", Path: "dump.sql", FullPath: path, Type: NodeTypeFile, Size: int64(len(content))}
+	readFileNode(node, IngestionOptions{MaxFileSize: 1024})
+	if node.Type != NodeTypeTooLarge || node.Content != "" {
+		t.Errorf("without truncation: Type = %q, content length %d; want too-large and empty", node.Type, len(node.Content))
+	}
+}
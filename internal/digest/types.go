@@ -2,50 +2,128 @@ package digest
 
 import (
 	"io/fs"
+	"time"
 
+	"github.com/ga1az/pathdigest/internal/deps"
 	"github.com/ga1az/pathdigest/internal/gitutil"
+	"github.com/ga1az/pathdigest/internal/redact"
 )
 
 type IngestionOptions struct {
-	Source          string
-	OutputFile      string
-	MaxFileSize     int64
-	ExcludePatterns []string
-	IncludePatterns []string
-	Branch          string
+	Source           string
+	OutputFile       string
+	MaxFileSize      int64
+	ExcludePatterns  []string
+	IncludePatterns  []string
+	Branch           string
+	TruncateLarge    TruncateSpec
+	Order            string
+	PriorityPatterns []string
+	Model            *ModelProfile
+	LineNumbers      bool
+	StripComments    bool
+	KeepDocComments  bool
+	// StripLicenseHeaders removes leading license comments: those with a
+	// license marker, or containing LicenseTemplate when it is set.
+	StripLicenseHeaders bool
+	LicenseTemplate     string
+	// Outline lists the languages whose files are reduced to declarations;
+	// files matching OutlineKeep keep their full content.
+	Outline     []string
+	OutlineKeep []string
+	// Compact reduces whitespace; CompactTabWidth > 0 also converts
+	// leading indentation to tabs of that many columns.
+	Compact         bool
+	CompactTabWidth int
+	// Secrets is one of SecretsModes; SecretsAllowlist suppresses known
+	// false positives.
+	Secrets          string
+	SecretsAllowlist *redact.Allowlist
+	// RedactPII replaces personal data with placeholders such as <EMAIL_1>,
+	// using PIIRules, or the built-in rules when PIIRules is nil.
+	RedactPII bool
+	PIIRules  []redact.PIIRule
+	// Dedupe includes the content of identical files once; later copies
+	// name the file they duplicate instead.
+	Dedupe bool
+	// Deps adds the direct dependencies declared in manifests such as
+	// go.mod and package.json, with versions resolved from lockfiles.
+	Deps bool
+
+	// piiRedactor is shared by every file of one ingestion, so that a value
+	// keeps its placeholder across files.
+	piiRedactor *redact.PIIRedactor
+	// onFile is called for every regular file as soon as it has been read,
+	// in content order. StreamNDJSON uses it to write file records during
+	// the walk.
+	onFile func(node *FileNode)
 }
 
 type FileNodeType string
 
 const (
-	NodeTypeFile     FileNodeType = "file"
-	NodeTypeDir      FileNodeType = "directory"
-	NodeTypeSymlink  FileNodeType = "symlink"
-	NodeTypeNotText  FileNodeType = "non-text"
-	NodeTypeTooLarge FileNodeType = "too-large"
-	NodeTypeExcluded FileNodeType = "excluded"
+	NodeTypeFile      FileNodeType = "file"
+	NodeTypeDir       FileNodeType = "directory"
+	NodeTypeSymlink   FileNodeType = "symlink"
+	NodeTypeNotText   FileNodeType = "non-text"
+	NodeTypeTooLarge  FileNodeType = "too-large"
+	NodeTypeExcluded  FileNodeType = "excluded"
+	NodeTypeTruncated FileNodeType = "truncated"
 )
 
 type FileNode struct {
-	Name     string
-	Path     string
-	FullPath string
-	Type     FileNodeType
-	Size     int64
-	Mode     fs.FileMode
-	Content  string
-	Children []*FileNode
-	Error    error
-	Depth    int
+	Name       string
+	Path       string
+	FullPath   string
+	Type       FileNodeType
+	Size       int64
+	Mode       fs.FileMode
+	ModTime    time.Time
+	SHA256     string
+	Content    string
+	Children   []*FileNode
+	Error      error
+	Depth      int
+	Truncation *Truncation
+	Transforms []TransformStat
+	Secrets    []redact.Finding
+	// PIIRedactions counts the personal data redacted per label.
+	PIIRedactions map[string]int
+	// DuplicateOf, set with IngestionOptions.Dedupe, is the slash path of an
+	// earlier file with the same content.
+	DuplicateOf string
+	// LicenseHeader is the normalized text of the license header removed
+	// with IngestionOptions.StripLicenseHeaders.
+	LicenseHeader string
+	// Description summarizes a non-text file whose format is recognized,
+	// such as "PNG image, 640x480".
+	Description string
+}
+
+// Truncation describes the part of an oversized file that was left out
+// when only its head and tail were kept.
+type Truncation struct {
+	HeadLines    int
+	TailLines    int
+	OmittedLines int
+	OmittedBytes int64
 }
 
 type Result struct {
 	Summary       string
 	TreeStructure string
-	FileContents  string
-	RootNode      *FileNode
-	TotalFiles    int
-	TotalSize     int64
-	TokenCount    int
-	GitInfo       *gitutil.GitURLParts
+	// DependencyList is the Dependencies section of the text digest, set
+	// by FormatOutput.
+	DependencyList string
+	FileContents   string
+	RootNode       *FileNode
+	TotalFiles     int
+	TotalSize      int64
+	TokenCount     int
+	// OutputTokens is the estimated size of the rendered digest, including
+	// tree, summary and markup, set by Render when a model is selected.
+	OutputTokens int
+	GitInfo      *gitutil.GitURLParts
+	Churn        map[string]int
+	Dependencies []deps.Manifest
 }
@@ -0,0 +1,124 @@
+package digest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatXML renders the digest as document-tagged XML, the layout recommended
+// for long-context LLM prompts: each file becomes a <document> with its
+// <source> and <document_content>.
+func (r *Result) FormatXML(opts IngestionOptions) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("<digest>\n")
+
+	sb.WriteString("<summary>\n")
+	writeXMLText(&sb, formatSummaryRows(r.summaryRows(opts)))
+	sb.WriteString("</summary>\n")
+
+	var sbTree strings.Builder
+	r.writeTree(&sbTree)
+	if sbTree.Len() > 0 {
+		sb.WriteString("<directory_structure>\n")
+		writeXMLText(&sb, sbTree.String())
+		sb.WriteString("</directory_structure>\n")
+	}
+
+	sb.WriteString("<documents>\n")
+	index := 0
+	for _, node := range r.contentNodes(opts) {
+		if node.Type == NodeTypeFile && node.Content == "" {
+			continue
+		}
+		index++
+		writeXMLDocument(&sb, node, index, opts)
+	}
+	sb.WriteString("</documents>\n")
+
+	sb.WriteString("</digest>\n")
+	return []byte(sb.String())
+}
+
+func writeXMLDocument(sb *strings.Builder, node *FileNode, index int, opts IngestionOptions) {
+	sb.WriteString(fmt.Sprintf("<document index=\"%d\">\n", index))
+	sb.WriteString("<source>")
+	writeXMLText(sb, filepath.ToSlash(node.Path))
+	sb.WriteString("</source>\n")
+
+	switch {
+	case node.DuplicateOf != "":
+		sb.WriteString("<document_note>identical to ")
+		writeXMLText(sb, node.DuplicateOf)
+		sb.WriteString("</document_note>\n")
+	case node.Type == NodeTypeFile, node.Type == NodeTypeTruncated:
+		if node.Type == NodeTypeTruncated {
+			sb.WriteString("<document_note>truncated</document_note>\n")
+		}
+		sb.WriteString("<document_content>\n")
+		content := displayContent(node, opts)
+		writeXMLCDATA(sb, content)
+		if !strings.HasSuffix(content, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("</document_content>\n")
+	case node.Type == NodeTypeNotText, node.Type == NodeTypeTooLarge:
+		sb.WriteString(fmt.Sprintf("<document_note>%s - content not included</document_note>\n", node.Type))
+		if node.Description != "" {
+			sb.WriteString("<document_description>")
+			writeXMLText(sb, node.Description)
+			sb.WriteString("</document_description>\n")
+		}
+	}
+
+	sb.WriteString("</document>\n")
+}
+
+// xmlTextEscaper escapes character data but keeps line feeds literal, so
+// that the summary and the tree stay readable. Carriage returns are escaped
+// because parsers would otherwise normalize them away.
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+func writeXMLText(sb *strings.Builder, s string) {
+	xmlTextEscaper.WriteString(sb, sanitizeXMLChars(s))
+}
+
+// writeXMLCDATA writes s as CDATA. Any "]]>" inside s is split across two
+// sections, and characters that XML 1.0 forbids are replaced with U+FFFD.
+func writeXMLCDATA(sb *strings.Builder, s string) {
+	if s == "" {
+		return
+	}
+	s = sanitizeXMLChars(s)
+	sb.WriteString("<![CDATA[")
+	sb.WriteString(strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>"))
+	sb.WriteString("]]>")
+}
+
+func sanitizeXMLChars(s string) string {
+	valid := utf8.ValidString(s)
+	for _, r := range s {
+		if !isXMLChar(r) {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if !isXMLChar(r) {
+			return utf8.RuneError
+		}
+		return r
+	}, s)
+}
+
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		(r >= 0x20 && r <= 0xD7FF) ||
+		(r >= 0xE000 && r <= 0xFFFD) ||
+		(r >= 0x10000 && r <= 0x10FFFF)
+}
@@ -0,0 +1,70 @@
+package digest
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+type xmlTestDigest struct {
+	Summary   string `xml:"summary"`
+	Tree      string `xml:"directory_structure"`
+	Documents []struct {
+		Index   int    `xml:"index,attr"`
+		Source  string `xml:"source"`
+		Note    string `xml:"document_note"`
+		Content string `xml:"document_content"`
+	} `xml:"documents>document"`
+}
+
+func TestFormatXML_RoundTrip(t *testing.T) {
+	tricky := "if a < b && c > d {\n\ts := \"]]>\"\n}\x01\n"
+	root := &FileNode{
+		Name: "project",
+		Path: ".",
+		Type: NodeTypeDir,
+		Children: []*FileNode{
+			{Name: "a&b.go", Path: "a&b.go", Type: NodeTypeFile, Size: 40, Content: tricky},
+			{Name: "img.png", Path: "img.png", Type: NodeTypeNotText, Size: 10},
+		},
+	}
+	r := &Result{RootNode: root, TotalFiles: 2, TotalSize: 50}
+
+	out := r.FormatXML(IngestionOptions{Source: "<src>"})
+
+	var parsed xmlTestDigest
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("FormatXML produced invalid XML: %v\n%s", err, out)
+	}
+
+	if !strings.Contains(parsed.Summary, "Source Directory: <src>") {
+		t.Errorf("summary = %q, want unescaped source", parsed.Summary)
+	}
+	if !strings.Contains(parsed.Tree, "a&b.go") {
+		t.Errorf("tree = %q, want file name", parsed.Tree)
+	}
+	raw := string(out)
+	start, end := strings.Index(raw, "<directory_structure>"), strings.Index(raw, "</directory_structure>")
+	if start < 0 || end < start || strings.Count(raw[start:end], "\n") < 3 || strings.Contains(raw, "&#xA;") {
+		t.Errorf("directory structure is not written with literal newlines:\n%s", raw)
+	}
+	if !strings.Contains(raw, "├── a&amp;b.go\n") {
+		t.Errorf("tree line not escaped as expected:\n%s", raw)
+	}
+
+	if len(parsed.Documents) != 2 {
+		t.Fatalf("got %d documents, want 2", len(parsed.Documents))
+	}
+
+	doc := parsed.Documents[0]
+	if doc.Index != 1 || doc.Source != "a&b.go" {
+		t.Errorf("document 1 = index %d source %q", doc.Index, doc.Source)
+	}
+	wantContent := "\n" + strings.Replace(tricky, "\x01", "�", 1)
+	if doc.Content != wantContent {
+		t.Errorf("document content = %q, want %q", doc.Content, wantContent)
+	}
+	if parsed.Documents[1].Note != "non-text - content not included" {
+		t.Errorf("document 2 note = %q", parsed.Documents[1].Note)
+	}
+}
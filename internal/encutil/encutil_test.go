@@ -0,0 +1,278 @@
+package encutil
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type testFile struct {
+	Path    string  `json:"path"`
+	Size    int64   `json:"size,omitempty"`
+	Content string  `json:"content"`
+	Ratio   float64 `json:"ratio,omitempty"`
+	Skipped string  `json:"-"`
+}
+
+type testDoc struct {
+	Name     string            `json:"name"`
+	Tags     []string          `json:"tags"`
+	Meta     *testMeta         `json:"meta,omitempty"`
+	Counts   map[string]int    `json:"counts,omitempty"`
+	Files    []testFile        `json:"files"`
+	Children []*testDoc        `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels"`
+}
+
+type testMeta struct {
+	Enabled bool `json:"enabled"`
+}
+
+func sampleDoc() testDoc {
+	return testDoc{
+		Name:   "true",
+		Tags:   []string{"a", "b c"},
+		Meta:   &testMeta{Enabled: true},
+		Counts: map[string]int{"z": 2, "a": 1},
+		Files: []testFile{
+			{Path: "main.go", Size: 13, Content: "package main\n", Ratio: 2},
+			{Path: "x.txt", Content: "  leading\nno newline", Skipped: "hidden"},
+		},
+		Children: []*testDoc{{Name: "child", Tags: []string{}}},
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	out, err := MarshalYAML(sampleDoc())
+	if err != nil {
+		t.Fatalf("MarshalYAML returned error: %v", err)
+	}
+
+	want := `name: "true"
+tags:
+  - a
+  - "b c"
+meta:
+  enabled: true
+counts:
+  a: 1
+  z: 2
+files:
+  - path: main.go
+    size: 13
+    content: |
+      package main
+    ratio: 2
+  - path: x.txt
+    content: |2-
+        leading
+      no newline
+children:
+  - name: child
+    tags: []
+    files: null
+    labels: null
+labels: null
+`
+	if string(out) != want {
+		t.Errorf("MarshalYAML() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestMarshalTOML(t *testing.T) {
+	out, err := MarshalTOML(sampleDoc())
+	if err != nil {
+		t.Fatalf("MarshalTOML returned error: %v", err)
+	}
+
+	want := `name = "true"
+tags = ["a", "b c"]
+
+[meta]
+enabled = true
+
+[counts]
+a = 1
+z = 2
+
+[[files]]
+path = "main.go"
+size = 13
+content = '''
+package main
+'''
+ratio = 2.0
+
+[[files]]
+path = "x.txt"
+content = '''
+  leading
+no newline'''
+
+[[children]]
+name = "child"
+tags = []
+files = []
+`
+	if string(out) != want {
+		t.Errorf("MarshalTOML() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestTOMLString_FallsBackToBasic(t *testing.T) {
+	got := tomlString("a ''' b\r\nc\x01\n")
+	want := "\"\"\"\na ''' b\\r\nc\\u0001\n\"\"\""
+	if got != want {
+		t.Errorf("tomlString() = %q, want %q", got, want)
+	}
+}
+
+func TestYAMLString_ControlCharsAreQuoted(t *testing.T) {
+	got := yamlString("a\r\nb\n", 0)
+	if !strings.HasPrefix(got, `"`) {
+		t.Errorf("yamlString() = %q, want a double-quoted scalar", got)
+	}
+}
+
+// roundTripStrings are file contents that stress the choice between block,
+// literal and quoted forms.
+var roundTripStrings = []string{
+	"\n",
+	"\n\n",
+	"\n\n\n",
+	"a\n",
+	"a",
+	"a\n\n",
+	"\n\nlead\n",
+	"  indented\n\n\n  trailing   \n",
+	"line1\n\n\n  line2   ",
+	"it's\n'",
+	"tab\tand \"quotes\" \\ \n",
+	"bad \xff utf-8\n",
+}
+
+func TestYAMLString_RoundTrip(t *testing.T) {
+	for _, s := range roundTripStrings {
+		out := yamlString(s, 0)
+		if !utf8.ValidString(out) {
+			t.Errorf("yamlString(%q) = %q, not valid UTF-8", s, out)
+			continue
+		}
+		if got, want := decodeYAMLString(t, out), strings.ToValidUTF8(s, "�"); got != want {
+			t.Errorf("yamlString(%q) = %q, decodes to %q", s, out, got)
+		}
+	}
+}
+
+func TestTOMLString_RoundTrip(t *testing.T) {
+	for _, s := range roundTripStrings {
+		out := tomlString(s)
+		if !utf8.ValidString(out) {
+			t.Errorf("tomlString(%q) = %q, not valid UTF-8", s, out)
+			continue
+		}
+		if got, want := decodeTOMLString(t, out), strings.ToValidUTF8(s, "�"); got != want {
+			t.Errorf("tomlString(%q) = %q, decodes to %q", s, out, got)
+		}
+	}
+}
+
+// decodeYAMLString decodes the scalar forms yamlString emits at indent 0:
+// plain, double-quoted (JSON-compatible) and literal block scalars.
+func decodeYAMLString(t *testing.T, s string) string {
+	t.Helper()
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var out string
+		if err := json.Unmarshal([]byte(s), &out); err != nil {
+			t.Fatalf("invalid double-quoted scalar %q: %v", s, err)
+		}
+		return out
+	case !strings.HasPrefix(s, "|"):
+		return s
+	}
+
+	header, body, _ := strings.Cut(s, "\n")
+	indent, chomp := 0, byte(0)
+	for i := 1; i < len(header); i++ {
+		if c := header[i]; c >= '1' && c <= '9' {
+			indent = int(c - '0')
+		} else {
+			chomp = c
+		}
+	}
+	lines := strings.Split(body, "\n")
+	if indent == 0 {
+		for _, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				indent = len(line) - len(strings.TrimLeft(line, " "))
+				break
+			}
+		}
+	}
+	last := -1
+	for i, line := range lines {
+		if len(line) > indent {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = ""
+		}
+		if lines[i] != "" {
+			last = i
+		}
+	}
+
+	text := strings.Join(lines[:last+1], "\n")
+	if last >= 0 && chomp != '-' {
+		text += "\n"
+	}
+	if chomp == '+' {
+		text += strings.Repeat("\n", len(lines)-last-1)
+	}
+	return text
+}
+
+// decodeTOMLString decodes the string forms tomlString emits.
+func decodeTOMLString(t *testing.T, s string) string {
+	t.Helper()
+	switch {
+	case strings.HasPrefix(s, "'''\n"):
+		return strings.TrimSuffix(strings.TrimPrefix(s, "'''\n"), "'''")
+	case strings.HasPrefix(s, `"""`+"\n"):
+		return tomlUnescape(t, strings.TrimSuffix(strings.TrimPrefix(s, `"""`+"\n"), `"""`))
+	case strings.HasPrefix(s, `"`):
+		return tomlUnescape(t, strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`))
+	}
+	t.Fatalf("unexpected TOML string %q", s)
+	return ""
+}
+
+func tomlUnescape(t *testing.T, s string) string {
+	t.Helper()
+	escapes := map[byte]string{'"': `"`, '\\': `\`, 'n': "\n", 't': "\t", 'r': "\r", 'b': "\b", 'f': "\f"}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i < len(s) && s[i] == 'u' && i+4 < len(s) {
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				t.Fatalf("bad escape in %q", s)
+			}
+			sb.WriteRune(rune(r))
+			i += 4
+			continue
+		}
+		e, ok := escapes[s[i]]
+		if !ok {
+			t.Fatalf("bad escape in %q", s)
+		}
+		sb.WriteString(e)
+	}
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+// Package encutil encodes Go values as YAML and TOML using the same field
+// names and omitempty rules as encoding/json, so every structured output
+// format shares one schema. It can also describe that schema as JSON Schema
+// and validate decoded JSON documents against it.
+package encutil
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// jsonFields returns the exported fields of struct value v as encoding/json
+// would see them: renamed by json tags, "-" skipped, omitempty honored and
+// embedded structs flattened.
+func jsonFields(v reflect.Value) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(fv)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.Contains(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		fields = append(fields, field{name: name, value: fv})
+	}
+	return fields
+}
+
+// mapFields returns the entries of map value v sorted by key.
+func mapFields(v reflect.Value) []field {
+	fields := make([]field, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		fields = append(fields, field{name: keyString(iter.Key()), value: iter.Value()})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields
+}
+
+func keyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return formatScalar(k)
+}
+
+// isEmptyValue mirrors encoding/json's omitempty definition.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// indirect dereferences pointers and interfaces. It reports false for nil.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+func isCompound(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
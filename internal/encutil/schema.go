@@ -0,0 +1,163 @@
+package encutil
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema dialect produced by GenerateSchema.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema that GenerateSchema emits and Validate
+// understands.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // bool or *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+type typeField struct {
+	name      string
+	typ       reflect.Type
+	omitempty bool
+}
+
+// GenerateSchema derives a JSON Schema from the encoding/json view of v's
+// type. Named struct types other than the root are placed in $defs and
+// referenced, which also covers recursive types. Fields without omitempty
+// are required; objects allow additional properties, so documents from later
+// versions that add fields still validate against an older schema.
+func GenerateSchema(v any) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	g := &schemaGenerator{root: t, defs: make(map[string]*Schema)}
+	s := g.structSchema(t)
+	s.Schema = SchemaDraft
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+type schemaGenerator struct {
+	root reflect.Type
+	defs map[string]*Schema
+}
+
+// schemaFor returns the schema of a value of type t. A nil-able value that is
+// always present in the output (no omitempty) may also be null.
+func (g *schemaGenerator) schemaFor(t reflect.Type, nullable bool) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem(), nullable)
+	case reflect.Struct:
+		s := g.structRef(t)
+		if nullable {
+			return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		s := &Schema{Type: "array", Items: g.schemaFor(t.Elem(), false)}
+		if nullable && t.Kind() == reflect.Slice {
+			s.Type = []string{"array", "null"}
+		}
+		return s
+	case reflect.Map:
+		s := &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem(), false)}
+		if nullable {
+			s.Type = []string{"object", "null"}
+		}
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	return &Schema{}
+}
+
+func (g *schemaGenerator) structRef(t reflect.Type) *Schema {
+	if t == g.root {
+		return &Schema{Ref: "#"}
+	}
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+	if _, ok := g.defs[t.Name()]; !ok {
+		// Reserve the name first so recursive references terminate.
+		g.defs[t.Name()] = nil
+		g.defs[t.Name()] = g.structSchema(t)
+	}
+	return &Schema{Ref: "#/$defs/" + t.Name()}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: true,
+	}
+	for _, f := range jsonTypeFields(t) {
+		nilable := f.typ.Kind() == reflect.Pointer || f.typ.Kind() == reflect.Slice || f.typ.Kind() == reflect.Map
+		s.Properties[f.name] = g.schemaFor(f.typ, nilable && !f.omitempty)
+		if !f.omitempty {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+// jsonTypeFields is the type-level counterpart of jsonFields: it lists the
+// fields encoding/json would emit for struct type t.
+func jsonTypeFields(t reflect.Type) []typeField {
+	var fields []typeField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, jsonTypeFields(ft)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, typeField{name: name, typ: sf.Type, omitempty: strings.Contains(opts, "omitempty")})
+	}
+	return fields
+}
@@ -0,0 +1,103 @@
+package encutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	s := GenerateSchema(testDoc{})
+
+	if s.Schema != SchemaDraft || s.Type != "object" {
+		t.Fatalf("unexpected root: $schema=%q type=%v", s.Schema, s.Type)
+	}
+	wantRequired := []string{"name", "tags", "files", "labels"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("required = %v, want %v", s.Required, wantRequired)
+	}
+	if !reflect.DeepEqual(s.Properties["tags"].Type, []string{"array", "null"}) {
+		t.Errorf("tags type = %v, want nullable array", s.Properties["tags"].Type)
+	}
+	if s.Properties["children"].Items.Ref != "#" {
+		t.Errorf("recursive children items = %+v, want $ref #", s.Properties["children"].Items)
+	}
+	if s.Properties["meta"].Ref != "#/$defs/testMeta" || s.Defs["testMeta"] == nil {
+		t.Errorf("meta should reference $defs/testMeta, got %+v", s.Properties["meta"])
+	}
+	if _, ok := s.Properties["Skipped"]; ok {
+		t.Error(`field tagged json:"-" appears in the schema`)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("schema does not marshal: %v", err)
+	}
+}
+
+func TestValidate_GeneratedDocumentsPass(t *testing.T) {
+	s := GenerateSchema(testDoc{})
+	for _, doc := range []testDoc{sampleDoc(), {}} {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, err := Validate(s, data)
+		if err != nil {
+			t.Fatalf("Validate returned error: %v", err)
+		}
+		if len(errs) > 0 {
+			t.Errorf("valid document rejected: %v\n%s", errs, data)
+		}
+	}
+}
+
+func TestValidate_Violations(t *testing.T) {
+	s := GenerateSchema(testDoc{})
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"missing required", `{"name":"x","tags":[],"files":[]}`, `/: missing required property "labels"`},
+		{"wrong type", `{"name":1,"tags":[],"files":[],"labels":{}}`, "/name: expected string, got integer"},
+		{"nested item", `{"name":"x","tags":[],"files":[{"path":"a","content":"b","size":1.5}],"labels":{}}`, "/files/0/size: expected integer, got number"},
+		{"recursive", `{"name":"x","tags":[],"files":[],"labels":{},"children":[{"name":"y"}]}`, `/children/0: missing required property "files"`},
+		{"map values", `{"name":"x","tags":[],"files":[],"labels":{"a":1}}`, "/labels/a: expected string, got integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate(s, []byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Validate returned error: %v", err)
+			}
+			var msgs []string
+			for _, e := range errs {
+				msgs = append(msgs, e.Error())
+			}
+			if !strings.Contains(strings.Join(msgs, "\n"), tt.want) {
+				t.Errorf("violations %q do not include %q", msgs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_AdditionalProperties(t *testing.T) {
+	doc := []byte(`{"name":"x","tags":[],"files":[],"labels":{},"extra":true}`)
+
+	s := GenerateSchema(testDoc{})
+	if errs, _ := Validate(s, doc); len(errs) > 0 {
+		t.Errorf("generated schema rejects an added property: %v", errs)
+	}
+
+	s.AdditionalProperties = false
+	errs, _ := Validate(s, doc)
+	if len(errs) != 1 || errs[0].Error() != `/extra: unknown property "extra"` {
+		t.Errorf("closed schema violations = %v, want the unknown property", errs)
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	if _, err := Validate(GenerateSchema(testDoc{}), []byte(`{"name":`)); err == nil {
+		t.Error("Validate accepted malformed JSON")
+	}
+}
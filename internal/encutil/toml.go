@@ -0,0 +1,228 @@
+package encutil
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var tomlBareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// MarshalTOML encodes a struct or map as a TOML document. Nested structs
+// become tables, slices of structs become arrays of tables, and multi-line
+// strings use TOML multi-line strings. Nil values are omitted because TOML
+// has no null.
+func MarshalTOML(v any) ([]byte, error) {
+	rv, ok := indirect(reflect.ValueOf(v))
+	if !ok || (rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map) {
+		return nil, fmt.Errorf("encutil: TOML documents must be a struct or map")
+	}
+
+	var sb strings.Builder
+	if err := writeTOMLTable(&sb, rv, nil); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+func writeTOMLTable(sb *strings.Builder, v reflect.Value, path []string) error {
+	var tables, arrays []field
+
+	// Plain key/value pairs must come before any sub-table header.
+	for _, f := range structOrMapFields(v) {
+		fv, ok := indirect(f.value)
+		if !ok || (fv.Kind() == reflect.Map && fv.IsNil()) {
+			continue
+		}
+		switch {
+		case fv.Kind() == reflect.Struct || fv.Kind() == reflect.Map:
+			tables = append(tables, field{name: f.name, value: fv})
+			continue
+		case isTableArray(fv):
+			arrays = append(arrays, field{name: f.name, value: fv})
+			continue
+		}
+
+		s, err := tomlValue(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(append(path, f.name), "."), err)
+		}
+		sb.WriteString(tomlKey(f.name))
+		sb.WriteString(" = ")
+		sb.WriteString(s)
+		sb.WriteString("\n")
+	}
+
+	for _, f := range tables {
+		childPath := append(append([]string{}, path...), f.name)
+		sb.WriteString(fmt.Sprintf("\n[%s]\n", tomlKeyPath(childPath)))
+		if err := writeTOMLTable(sb, f.value, childPath); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range arrays {
+		childPath := append(append([]string{}, path...), f.name)
+		for i := 0; i < f.value.Len(); i++ {
+			item, ok := indirect(f.value.Index(i))
+			if !ok {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("\n[[%s]]\n", tomlKeyPath(childPath)))
+			if err := writeTOMLTable(sb, item, childPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isTableArray reports whether v is a non-empty slice whose elements are
+// structs or maps.
+func isTableArray(v reflect.Value) bool {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() == 0 {
+		return false
+	}
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct || elem.Kind() == reflect.Map
+}
+
+func tomlValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return tomlString(v.String()), nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return formatScalar(v), nil
+	case reflect.Float32, reflect.Float64:
+		return tomlFloat(v.Float()), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok := indirect(v.Index(i))
+			if !ok {
+				return "", fmt.Errorf("encutil: TOML arrays cannot contain null")
+			}
+			if isCompound(item) && !(item.Kind() == reflect.Slice || item.Kind() == reflect.Array) {
+				return "", fmt.Errorf("encutil: unsupported TOML array element of kind %s", item.Kind())
+			}
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	return "", fmt.Errorf("encutil: unsupported TOML value of kind %s", v.Kind())
+}
+
+func tomlFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+func tomlKey(name string) string {
+	if tomlBareKeyRegex.MatchString(name) {
+		return name
+	}
+	return tomlBasicString(name)
+}
+
+func tomlKeyPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, p := range path {
+		keys[i] = tomlKey(p)
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlString picks the most readable TOML string form for s: a multi-line
+// literal string when possible, a multi-line basic string for other
+// multi-line content, and a basic string otherwise.
+func tomlString(s string) string {
+	if !strings.Contains(s, "\n") {
+		return tomlBasicString(s)
+	}
+	if canUseTOMLLiteral(s) {
+		return "'''\n" + s + "'''"
+	}
+	return `"""` + "\n" + tomlEscape(s, true) + `"""`
+}
+
+// canUseTOMLLiteral reports whether s can be written verbatim as a
+// multi-line literal string. Invalid UTF-8 cannot: it would make the whole
+// document invalid, while the basic string replaces it with U+FFFD as the
+// JSON output does.
+func canUseTOMLLiteral(s string) bool {
+	if !utf8.ValidString(s) || strings.Contains(s, "'''") || strings.HasSuffix(s, "'") {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '\t' || r == '\n':
+		case r == '\r':
+			if i+1 >= len(s) || s[i+1] != '\n' {
+				return false
+			}
+		case r < 0x20 || r == 0x7F:
+			return false
+		}
+	}
+	return true
+}
+
+func tomlBasicString(s string) string {
+	return `"` + tomlEscape(s, false) + `"`
+}
+
+func tomlEscape(s string, multiline bool) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			if multiline {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteString(`\n`)
+			}
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7F {
+				sb.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
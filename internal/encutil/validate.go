@@ -0,0 +1,202 @@
+package encutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError reports one schema violation at a JSON Pointer location.
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// Validate checks a JSON document against root and returns every violation
+// found. Only the keywords in Schema are supported; $ref may point to "#" or
+// to an entry of the root's $defs.
+func Validate(root *Schema, data []byte) ([]ValidationError, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the top-level value")
+	}
+
+	v := &validator{root: root}
+	v.validate(root, doc, "")
+	return v.errs, nil
+}
+
+type validator struct {
+	root *Schema
+	errs []ValidationError
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) resolve(ref string) (*Schema, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	s, ok := v.root.Defs[name]
+	if !ok || s == nil {
+		return nil, fmt.Errorf("unresolved $ref %q", ref)
+	}
+	return s, nil
+}
+
+func (v *validator) validate(s *Schema, doc any, path string) {
+	if s.Ref != "" {
+		target, err := v.resolve(s.Ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		v.validate(target, doc, path)
+		return
+	}
+
+	if len(s.AnyOf) > 0 {
+		for _, alt := range s.AnyOf {
+			sub := &validator{root: v.root}
+			sub.validate(alt, doc, path)
+			if len(sub.errs) == 0 {
+				return
+			}
+		}
+		v.fail(path, "value does not match any allowed schema")
+		return
+	}
+
+	if s.Type != nil && !matchesType(s.Type, doc) {
+		v.fail(path, "expected %s, got %s", typeNames(s.Type), jsonTypeOf(doc))
+		return
+	}
+
+	if s.Const != nil && !constEqual(s.Const, doc) {
+		v.fail(path, "expected constant %v, got %v", s.Const, doc)
+	}
+
+	switch val := doc.(type) {
+	case map[string]any:
+		v.validateObject(s, val, path)
+	case []any:
+		if s.Items != nil {
+			for i, item := range val {
+				v.validate(s.Items, item, fmt.Sprintf("%s/%d", path, i))
+			}
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, obj map[string]any, path string) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(path, "missing required property %q", name)
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := path + "/" + escapePointer(k)
+		if prop, ok := s.Properties[k]; ok {
+			v.validate(prop, obj[k], childPath)
+			continue
+		}
+		switch extra := s.AdditionalProperties.(type) {
+		case bool:
+			if !extra {
+				v.fail(childPath, "unknown property %q", k)
+			}
+		case *Schema:
+			v.validate(extra, obj[k], childPath)
+		}
+	}
+}
+
+func matchesType(t any, doc any) bool {
+	switch t := t.(type) {
+	case string:
+		return matchesTypeName(t, doc)
+	case []string:
+		for _, name := range t {
+			if matchesTypeName(name, doc) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func matchesTypeName(name string, doc any) bool {
+	actual := jsonTypeOf(doc)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func typeNames(t any) string {
+	if names, ok := t.([]string); ok {
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func jsonTypeOf(doc any) string {
+	switch val := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", doc)
+}
+
+func constEqual(want, doc any) bool {
+	if n, ok := doc.(json.Number); ok {
+		return n.String() == fmt.Sprint(want)
+	}
+	return fmt.Sprint(want) == fmt.Sprint(doc)
+}
+
+// escapePointer escapes a property name for use in a JSON Pointer.
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
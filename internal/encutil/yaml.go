@@ -0,0 +1,291 @@
+package encutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	yamlIndent = 2
+)
+
+var (
+	yamlPlainRegex    = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9_./@+-]*$`)
+	yamlReservedWords = map[string]bool{
+		"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+		"null": true, "y": true, "n": true, "~": true,
+	}
+)
+
+// MarshalYAML encodes v as a YAML document. Multi-line strings are written as
+// literal block scalars so file content stays readable.
+func MarshalYAML(v any) ([]byte, error) {
+	var sb strings.Builder
+	rv, ok := indirect(reflect.ValueOf(v))
+	if !ok {
+		return []byte("null\n"), nil
+	}
+
+	switch {
+	case rv.Kind() == reflect.Struct || rv.Kind() == reflect.Map:
+		if err := writeYAMLMapping(&sb, rv, 0); err != nil {
+			return nil, err
+		}
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		if err := writeYAMLSequence(&sb, rv, 0); err != nil {
+			return nil, err
+		}
+	default:
+		s, err := yamlScalar(rv, 0)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(s)
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String()), nil
+}
+
+func writeYAMLMapping(sb *strings.Builder, v reflect.Value, indent int) error {
+	fields := structOrMapFields(v)
+	if len(fields) == 0 {
+		sb.WriteString("{}\n")
+		return nil
+	}
+	for i, f := range fields {
+		if i > 0 {
+			sb.WriteString(strings.Repeat(" ", indent))
+		}
+		if err := writeYAMLEntry(sb, f, indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAMLEntry writes "key: value" assuming the cursor is already at the
+// entry's indentation.
+func writeYAMLEntry(sb *strings.Builder, f field, indent int) error {
+	sb.WriteString(yamlKey(f.name))
+	sb.WriteString(":")
+
+	v, ok := indirect(f.value)
+	if !ok {
+		sb.WriteString(" null\n")
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		if v.Kind() == reflect.Map && v.IsNil() {
+			sb.WriteString(" null\n")
+			return nil
+		}
+		if len(structOrMapFields(v)) == 0 {
+			sb.WriteString(" {}\n")
+			return nil
+		}
+		sb.WriteString("\n")
+		sb.WriteString(strings.Repeat(" ", indent+yamlIndent))
+		return writeYAMLMapping(sb, v, indent+yamlIndent)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			sb.WriteString(" null\n")
+			return nil
+		}
+		if v.Len() == 0 {
+			sb.WriteString(" []\n")
+			return nil
+		}
+		sb.WriteString("\n")
+		sb.WriteString(strings.Repeat(" ", indent+yamlIndent))
+		return writeYAMLSequence(sb, v, indent+yamlIndent)
+	}
+
+	s, err := yamlScalar(v, indent)
+	if err != nil {
+		return err
+	}
+	sb.WriteString(" ")
+	sb.WriteString(s)
+	sb.WriteString("\n")
+	return nil
+}
+
+// writeYAMLSequence writes the items of v assuming the cursor is already at
+// the sequence's indentation.
+func writeYAMLSequence(sb *strings.Builder, v reflect.Value, indent int) error {
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			sb.WriteString(strings.Repeat(" ", indent))
+		}
+		sb.WriteString("- ")
+
+		item, ok := indirect(v.Index(i))
+		if !ok {
+			sb.WriteString("null\n")
+			continue
+		}
+
+		switch item.Kind() {
+		case reflect.Struct, reflect.Map:
+			if err := writeYAMLMapping(sb, item, indent+yamlIndent); err != nil {
+				return err
+			}
+		case reflect.Slice, reflect.Array:
+			if item.Len() == 0 {
+				sb.WriteString("[]\n")
+				continue
+			}
+			if err := writeYAMLSequence(sb, item, indent+yamlIndent); err != nil {
+				return err
+			}
+		default:
+			s, err := yamlScalar(item, indent)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(s)
+			sb.WriteString("\n")
+		}
+	}
+	return nil
+}
+
+func structOrMapFields(v reflect.Value) []field {
+	if v.Kind() == reflect.Map {
+		return mapFields(v)
+	}
+	return jsonFields(v)
+}
+
+func yamlKey(name string) string {
+	if yamlPlainRegex.MatchString(name) && !yamlReservedWords[strings.ToLower(name)] {
+		return name
+	}
+	return yamlQuote(name)
+}
+
+// yamlScalar renders a scalar. indent is the indentation of the owning key,
+// used for block scalars.
+func yamlScalar(v reflect.Value, indent int) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return yamlString(v.String(), indent), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsInf(f, 1):
+			return ".inf", nil
+		case math.IsInf(f, -1):
+			return "-.inf", nil
+		case math.IsNaN(f):
+			return ".nan", nil
+		}
+		return formatScalar(v), nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return formatScalar(v), nil
+	}
+	return "", fmt.Errorf("encutil: unsupported YAML value of kind %s", v.Kind())
+}
+
+func yamlString(s string, indent int) string {
+	if strings.Contains(s, "\n") && canUseYAMLBlock(s) {
+		return yamlBlockScalar(s, indent+yamlIndent)
+	}
+	if yamlPlainRegex.MatchString(s) && !yamlReservedWords[strings.ToLower(s)] && !looksNumeric(s) {
+		return s
+	}
+	return yamlQuote(s)
+}
+
+// canUseYAMLBlock reports whether s survives a round trip through a literal
+// block scalar: valid UTF-8 with only printable characters, tabs and line
+// feeds, and at least one line that is not empty. A block of empty lines
+// alone decodes to "" under clip chomping.
+func canUseYAMLBlock(s string) bool {
+	if !utf8.ValidString(s) || strings.Trim(s, "\n") == "" {
+		return false
+	}
+	for _, r := range s {
+		if r == '\n' || r == '\t' {
+			continue
+		}
+		if r < 0x20 || r == 0x7F || r == 0xFEFF || (r >= 0x80 && r <= 0x9F) || r == 0x2028 || r == 0x2029 {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlBlockScalar writes s as a literal block scalar with an explicit
+// chomping indicator, and an indentation indicator when the first line would
+// otherwise be misread as indentation.
+func yamlBlockScalar(s string, indent int) string {
+	var header strings.Builder
+	header.WriteString("|")
+
+	firstLine, _, _ := strings.Cut(s, "\n")
+	if strings.TrimLeft(firstLine, " \t") != firstLine || strings.TrimSpace(firstLine) == "" {
+		header.WriteString(strconv.Itoa(yamlIndent))
+	}
+
+	body := s
+	switch {
+	case !strings.HasSuffix(s, "\n"):
+		header.WriteString("-")
+	case strings.HasSuffix(s, "\n\n"):
+		header.WriteString("+")
+		body = strings.TrimSuffix(s, "\n")
+	default:
+		body = strings.TrimSuffix(s, "\n")
+	}
+
+	pad := strings.Repeat(" ", indent)
+	var sb strings.Builder
+	sb.WriteString(header.String())
+	for _, line := range strings.Split(body, "\n") {
+		sb.WriteString("\n")
+		if line != "" {
+			sb.WriteString(pad)
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+func yamlQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string cannot fail.
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func looksNumeric(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil || strings.HasPrefix(s, ".") && len(s) > 1
+}
+
+func formatScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -1,16 +1,33 @@
 package fsutil
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
 	maxBytesToDetectText = 1024
+	tailReadChunkSize    = 32 * 1024
 )
 
+// HeadTail holds the leading and trailing lines of a file together with
+// the size of the section that was left out between them.
+type HeadTail struct {
+	Head         string
+	Tail         string
+	HeadLines    int
+	TailLines    int
+	OmittedLines int
+	OmittedBytes int64
+}
+
 func IsTextFile(path string) (bool, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -45,6 +62,188 @@ func ReadFileContent(path string) (string, error) {
 	return string(content), nil
 }
 
+// FileSHA256 returns the hex-encoded SHA-256 digest of the file at path,
+// reading it in a streaming fashion.
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DetectEncoding names the text encoding of data: "utf-8-bom", "utf-16le" or
+// "utf-16be" when a byte order mark is present, otherwise "ascii", "utf-8" or
+// "unknown" for bytes that are not valid UTF-8.
+func DetectEncoding(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8-bom"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+	ascii := true
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "ascii"
+	}
+	if utf8.Valid(data) {
+		return "utf-8"
+	}
+	return "unknown"
+}
+
+// ReadHeadTail reads up to headLines lines from the start of the file and up
+// to tailLines lines from its end without loading the rest into memory.
+// When maxBytes is positive, each part is additionally capped to half of it,
+// so a single huge line cannot defeat the limit.
+func ReadHeadTail(path string, headLines, tailLines int, maxBytes int64) (*HeadTail, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+
+	partLimit := size
+	if maxBytes > 0 && maxBytes/2 < partLimit {
+		partLimit = maxBytes / 2
+	}
+
+	ht := &HeadTail{}
+
+	var head bytes.Buffer
+	reader := bufio.NewReader(io.LimitReader(file, partLimit))
+	for ht.HeadLines < headLines {
+		line, errLine := reader.ReadBytes('\n')
+		head.Write(line)
+		if len(line) > 0 {
+			ht.HeadLines++
+		}
+		if errLine == io.EOF {
+			break
+		}
+		if errLine != nil {
+			return nil, errLine
+		}
+	}
+	ht.Head = head.String()
+	headEnd := int64(head.Len())
+
+	tailStart, err := findTailStart(file, size, headEnd, tailLines, partLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	middle := io.NewSectionReader(file, headEnd, tailStart-headEnd)
+	omittedLines, lastByte, err := countLines(middle)
+	if err != nil {
+		return nil, err
+	}
+	if tailStart-headEnd > 0 && lastByte != '\n' {
+		omittedLines++
+	}
+	ht.OmittedLines = omittedLines
+	ht.OmittedBytes = tailStart - headEnd
+
+	tail, err := io.ReadAll(io.NewSectionReader(file, tailStart, size-tailStart))
+	if err != nil {
+		return nil, err
+	}
+	ht.Tail = string(tail)
+	if len(tail) > 0 {
+		ht.TailLines = bytes.Count(tail, []byte("\n"))
+		if tail[len(tail)-1] != '\n' {
+			ht.TailLines++
+		}
+	}
+
+	return ht, nil
+}
+
+// findTailStart scans backwards from the end of the file and returns the
+// offset where the last tailLines lines begin, never going below floor.
+func findTailStart(file *os.File, size, floor int64, tailLines int, limit int64) (int64, error) {
+	if tailLines <= 0 || size <= floor {
+		return size, nil
+	}
+
+	minStart := floor
+	if size-limit > minStart {
+		minStart = size - limit
+	}
+
+	end := size
+	// A trailing newline terminates the last line; it does not start a new one.
+	var last [1]byte
+	if _, err := file.ReadAt(last[:], size-1); err != nil {
+		return 0, err
+	}
+	if last[0] == '\n' {
+		end--
+	}
+
+	buf := make([]byte, tailReadChunkSize)
+	found := 0
+	for end > minStart {
+		start := end - int64(len(buf))
+		if start < minStart {
+			start = minStart
+		}
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] == '\n' {
+				found++
+				if found == tailLines {
+					return start + int64(i) + 1, nil
+				}
+			}
+		}
+		end = start
+	}
+	return minStart, nil
+}
+
+func countLines(r io.Reader) (int, byte, error) {
+	buf := make([]byte, tailReadChunkSize)
+	count := 0
+	var lastByte byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			count += bytes.Count(buf[:n], []byte("\n"))
+			lastByte = buf[n-1]
+		}
+		if err == io.EOF {
+			return count, lastByte, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+}
+
 func GetRelativePath(basePath, targetPath string) (string, error) {
 	absBasePath, err := filepath.Abs(basePath)
 	if err != nil {
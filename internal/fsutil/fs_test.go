@@ -1,8 +1,10 @@
 package fsutil
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -64,3 +66,88 @@ func TestGetRelativePath(t *testing.T) {
 		})
 	}
 }
+
+func TestReadHeadTail(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var sb strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	path := filepath.Join(tmpDir, "big.txt")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatalf("failed to create test file %q: %v", path, err)
+	}
+
+	tests := []struct {
+		name        string
+		head, tail  int
+		wantHead    string
+		wantTail    string
+		wantOmitted int
+		wantNoOmit  bool
+	}{
+		{"head and tail", 2, 3, "line 1\nline 2\n", "line 98\nline 99\nline 100\n", 95, false},
+		{"head only", 1, 0, "line 1\n", "", 99, false},
+		{"tail only", 0, 1, "", "line 100\n", 99, false},
+		{"covers whole file", 60, 60, sb.String(), "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ht, err := ReadHeadTail(path, tt.head, tt.tail, 0)
+			if err != nil {
+				t.Fatalf("ReadHeadTail returned error: %v", err)
+			}
+			if tt.wantNoOmit {
+				if ht.OmittedBytes != 0 || ht.Head+ht.Tail != sb.String() {
+					t.Errorf("expected whole file, got omitted=%d", ht.OmittedBytes)
+				}
+				return
+			}
+			if ht.Head != tt.wantHead {
+				t.Errorf("Head = %q, want %q", ht.Head, tt.wantHead)
+			}
+			if ht.Tail != tt.wantTail {
+				t.Errorf("Tail = %q, want %q", ht.Tail, tt.wantTail)
+			}
+			if ht.OmittedLines != tt.wantOmitted {
+				t.Errorf("OmittedLines = %d, want %d", ht.OmittedLines, tt.wantOmitted)
+			}
+		})
+	}
+}
+
+func TestFileSHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := FileSHA256(path)
+	if err != nil {
+		t.Fatalf("FileSHA256 returned error: %v", err)
+	}
+	want := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	if got != want {
+		t.Errorf("FileSHA256 = %s, want %s", got, want)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{[]byte("plain text\n"), "ascii"},
+		{[]byte("héllo\n"), "utf-8"},
+		{[]byte("\xEF\xBB\xBFhello"), "utf-8-bom"},
+		{[]byte("\xFF\xFEh\x00"), "utf-16le"},
+		{[]byte("\xFE\xFF\x00h"), "utf-16be"},
+		{[]byte("caf\xE9\n"), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := DetectEncoding(tt.data); got != tt.want {
+			t.Errorf("DetectEncoding(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
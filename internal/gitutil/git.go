@@ -2,6 +2,7 @@ package gitutil
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -135,7 +136,11 @@ func CheckRepoExists(repoURL string) (bool, error) {
 	return true, nil
 }
 
-func CloneRepo(repoURL, cloneDir, branch, commit string, subPath string, isBlob bool) (string, error) {
+// CloneRepo clones repoURL below cloneDir and returns the checkout path.
+// Without a commit the clone is shallow unless fullHistory is set; full
+// clones skip file contents outside the checkout (--filter=blob:none) so
+// that the history stays cheap to fetch.
+func CloneRepo(repoURL, cloneDir, branch, commit string, subPath string, isBlob, fullHistory bool) (string, error) {
 	repoName := strings.TrimSuffix(filepath.Base(repoURL), ".git")
 	targetPath := filepath.Join(cloneDir, repoName)
 
@@ -152,7 +157,14 @@ func CloneRepo(repoURL, cloneDir, branch, commit string, subPath string, isBlob
 	}
 
 	if commit == "" {
-		gitArgs = append(gitArgs, "--depth=1", "--single-branch")
+		if fullHistory {
+			if !isPartialClone {
+				gitArgs = append(gitArgs, "--filter=blob:none")
+			}
+			gitArgs = append(gitArgs, "--single-branch")
+		} else {
+			gitArgs = append(gitArgs, "--depth=1", "--single-branch")
+		}
 		if branch != "" {
 			gitArgs = append(gitArgs, "--branch", branch)
 		}
@@ -214,3 +226,35 @@ func FetchRemoteBranchList(repoURL string) ([]string, error) {
 	}
 	return branches, nil
 }
+
+// ErrShallowHistory is returned by FileChurn for shallow clones, whose
+// truncated history would make every file look equally changed.
+var ErrShallowHistory = errors.New("repository history is shallow")
+
+// FileChurn returns the number of commits touching each file under dir,
+// keyed by slash-separated paths relative to dir.
+func FileChurn(dir string) (map[string]int, error) {
+	shallow, err := exec.Command("git", "-C", dir, "rev-parse", "--is-shallow-repository").Output()
+	if err == nil && strings.TrimSpace(string(shallow)) == "true" {
+		return nil, ErrShallowHistory
+	}
+
+	// --no-renames skips rename detection, which would fetch the blobs of a
+	// blobless clone one by one; -z keeps unusual paths unquoted.
+	cmd := exec.Command("git", "-C", dir, "log", "--format=", "--name-only", "--no-renames", "-z", "--relative", "--", ".")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed for %s: %w (stderr: %s)", dir, err, stderr.String())
+	}
+
+	churn := make(map[string]int)
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name == "" {
+			continue
+		}
+		churn[name]++
+	}
+	return churn, nil
+}
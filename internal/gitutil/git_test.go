@@ -0,0 +1,110 @@
+package gitutil
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// gitRepo creates a repository in a temporary directory, failing the test
+// when a git command fails.
+func gitRepo(t *testing.T) (dir string, git func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir = t.TempDir()
+	git = func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	return dir, git
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory for %q: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %q: %v", name, err)
+	}
+}
+
+func TestFileChurn(t *testing.T) {
+	dir, git := gitRepo(t)
+
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, "src/my file.go", "package src\n")
+	writeFile(t, dir, "src/ü.go", "package src\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "first")
+
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "src/ü.go", "package src\n\nvar X = 1\n")
+	git("commit", "-q", "-a", "-m", "second")
+
+	writeFile(t, dir, "src/ü.go", "package src\n\nvar X = 2\n")
+	git("mv", "main.go", "app.go")
+	git("commit", "-q", "-a", "-m", "third")
+
+	churn, err := FileChurn(dir)
+	if err != nil {
+		t.Fatalf("FileChurn returned error: %v", err)
+	}
+	// Without rename detection the move counts as a deletion of main.go
+	// and an addition of app.go.
+	want := map[string]int{
+		"main.go":        3,
+		"app.go":         1,
+		"src/my file.go": 1,
+		"src/ü.go":       3,
+	}
+	if len(churn) != len(want) {
+		t.Errorf("FileChurn = %v, want %v", churn, want)
+	}
+	for path, n := range want {
+		if churn[path] != n {
+			t.Errorf("churn[%q] = %d, want %d", path, churn[path], n)
+		}
+	}
+
+	sub, err := FileChurn(filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("FileChurn(src) returned error: %v", err)
+	}
+	if sub["ü.go"] != 3 || sub["my file.go"] != 1 || len(sub) != 2 {
+		t.Errorf("FileChurn(src) = %v, want paths relative to src", sub)
+	}
+}
+
+func TestFileChurn_ShallowHistory(t *testing.T) {
+	dir, git := gitRepo(t)
+	for _, content := range []string{"one\n", "two\n"} {
+		writeFile(t, dir, "a.txt", content)
+		git("add", "-A")
+		git("commit", "-q", "-m", content)
+	}
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	cmd := exec.Command("git", "clone", "-q", "--depth", "1", "file://"+filepath.ToSlash(dir), clone)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("shallow clone failed: %v\n%s", err, out)
+	}
+
+	if _, err := FileChurn(clone); !errors.Is(err, ErrShallowHistory) {
+		t.Errorf("FileChurn on a shallow clone returned %v, want ErrShallowHistory", err)
+	}
+}
@@ -0,0 +1,93 @@
+package langutil
+
+import "strings"
+
+// StripComments removes the comments of src, written in lang, leaving string
+// literals, shebangs and tool directives untouched. Documentation comments
+// are kept when keepDocs is set. Lines that held only comments are dropped
+// and trailing whitespace left behind by a removed comment is trimmed. ok is
+// false when lang is not supported; src is then returned unchanged.
+func StripComments(lang, src string, keepDocs bool) (out string, ok bool) {
+	spans, ok := Lex(lang, src)
+	if !ok {
+		return src, false
+	}
+
+	var remove []Span
+	for _, s := range spans {
+		if s.Kind == SpanComment && !s.Directive && !(keepDocs && s.Doc) {
+			remove = append(remove, s)
+		}
+	}
+	if len(remove) == 0 {
+		return src, true
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(src))
+	next := 0
+	// prevBlank and dropped avoid leaving two blank lines where a comment
+	// block used to separate them.
+	prevBlank, dropped := true, false
+	for ls := 0; ls < len(src); ls = lineEnd(src, ls) + 1 {
+		le := lineEnd(src, ls)
+		line, touched := stripLine(src, ls, le, remove, &next)
+		blank := strings.TrimSpace(line) == ""
+		switch {
+		case touched && blank:
+			dropped = true
+			continue
+		case !touched && blank && dropped && prevBlank:
+			continue
+		case touched:
+			line = strings.TrimRight(line, " \t\r")
+			if le > ls && src[le-1] == '\r' {
+				line += "\r"
+			}
+		}
+		sb.WriteString(line)
+		if le < len(src) {
+			sb.WriteByte('\n')
+		}
+		prevBlank, dropped = blank, false
+	}
+	return sb.String(), true
+}
+
+// stripLine returns the text of line [ls, le) without the parts covered by
+// the spans in remove, starting the search at remove[*next]. touched reports
+// whether anything was removed.
+func stripLine(src string, ls, le int, remove []Span, next *int) (line string, touched bool) {
+	for *next < len(remove) && remove[*next].End <= ls {
+		*next++
+	}
+
+	var sb strings.Builder
+	pos := ls
+	// An empty line inside a block comment still counts as covered.
+	for k := *next; k < len(remove) && remove[k].Start < max(le, ls+1); k++ {
+		start, end := max(remove[k].Start, ls), min(remove[k].End, le)
+		sb.WriteString(src[pos:start])
+		// Keep tokens on both sides of an inline block comment apart, but
+		// leave a single space where the comment sat between two.
+		if start > ls && end < le {
+			switch before, after := isSpace(src[start-1]), isSpace(src[end]); {
+			case !before && !after:
+				sb.WriteByte(' ')
+			case before && after && src[end] != '\r':
+				end++
+			}
+		}
+		pos = end
+		touched = true
+	}
+	if !touched {
+		return src[ls:le], false
+	}
+	sb.WriteString(src[pos:le])
+	return sb.String(), true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,111 @@
+package langutil
+
+import "testing"
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		src      string
+		keepDocs bool
+		want     string
+	}{
+		{
+			name: "go line and block comments",
+			lang: "go",
+			src:  "package main\n\n// helper\nfunc f() int {\n\treturn 1 /* one */ + 2 // sum\n}\n",
+			want: "package main\n\nfunc f() int {\n\treturn 1 + 2\n}\n",
+		},
+		{
+			name: "go strings and directives",
+			lang: "go",
+			src:  "//go:build linux\n\npackage p\n\nvar s = \"// not a comment\"\nvar r = `/* raw */`\n",
+			want: "//go:build linux\n\npackage p\n\nvar s = \"// not a comment\"\nvar r = `/* raw */`\n",
+		},
+		{
+			name:     "go keeps doc comments",
+			lang:     "go",
+			src:      "package p\n\n// F does things.\nfunc F() {\n\t// inside\n\tg()\n}\n\ntype T struct {\n\t// N counts.\n\tN int\n}\n",
+			keepDocs: true,
+			want:     "package p\n\n// F does things.\nfunc F() {\n\tg()\n}\n\ntype T struct {\n\t// N counts.\n\tN int\n}\n",
+		},
+		{
+			name: "inline block comment between tokens",
+			lang: "c",
+			src:  "int/*x*/y;\n",
+			want: "int y;\n",
+		},
+		{
+			name: "python shebang, strings and docstring",
+			lang: "python",
+			src:  "#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\n\"\"\"Module doc # kept.\"\"\"\nx = '#'  # comment\n",
+			want: "#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\n\"\"\"Module doc # kept.\"\"\"\nx = '#'\n",
+		},
+		{
+			name: "bash word-start hash and heredoc",
+			lang: "bash",
+			src:  "#!/bin/sh\necho $# a#b # note\ncat <<EOF\n# heredoc text\nEOF\n",
+			want: "#!/bin/sh\necho $# a#b\ncat <<EOF\n# heredoc text\nEOF\n",
+		},
+		{
+			name: "javascript regex and template literals",
+			lang: "javascript",
+			src:  "const re = /\\/\\/x/g; // re\nconst t = `// ${a}`; /* t */\n",
+			want: "const re = /\\/\\/x/g;\nconst t = `// ${a}`;\n",
+		},
+		{
+			name:     "rust nested blocks and doc comments",
+			lang:     "rust",
+			src:      "/// Doc.\nfn f() { /* a /* b */ c */ let s = r#\"// x\"#; }\n",
+			keepDocs: true,
+			want:     "/// Doc.\nfn f() { let s = r#\"// x\"#; }\n",
+		},
+		{
+			name: "sql dashes and doubled quotes",
+			lang: "sql",
+			src:  "SELECT 'it''s -- fine' -- trailing\nFROM t;\n",
+			want: "SELECT 'it''s -- fine'\nFROM t;\n",
+		},
+		{
+			name: "yaml block scalar",
+			lang: "yaml",
+			src:  "# header\nkey: value # note\nscript: |\n  echo # kept\nurl: http://x#frag\n",
+			want: "key: value\nscript: |\n  echo # kept\nurl: http://x#frag\n",
+		},
+		{
+			name: "no doubled blank line",
+			lang: "go",
+			src:  "package p\n\n// one\n// two\n\nvar x int\n",
+			want: "package p\n\nvar x int\n",
+		},
+		{
+			name: "crlf line endings",
+			lang: "go",
+			src:  "package p\r\nvar x int // c\r\n",
+			want: "package p\r\nvar x int\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StripComments(tt.lang, tt.src, tt.keepDocs)
+			if !ok {
+				t.Fatalf("StripComments(%q) reported the language as unsupported", tt.lang)
+			}
+			if got != tt.want {
+				t.Errorf("StripComments(%q) =\n%q\nwant\n%q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripComments_Unsupported(t *testing.T) {
+	src := "# Title\n"
+	got, ok := StripComments("markdown", src, false)
+	if ok || got != src {
+		t.Errorf("StripComments(markdown) = %q, %v; want input unchanged and false", got, ok)
+	}
+	if SupportsComments("markdown") || !SupportsComments("go") {
+		t.Error("SupportsComments reports the wrong languages")
+	}
+}
@@ -0,0 +1,111 @@
+package langutil
+
+import "strings"
+
+// Compact reduces the whitespace of src, written in lang: CRLF line endings
+// become LF, trailing whitespace is trimmed, blank lines at the start and end
+// are dropped and runs of blank lines collapse into one. When tabWidth is
+// positive, every tabWidth columns of leading indentation become a tab.
+//
+// Whitespace that carries meaning is left alone: lines inside string
+// literals, heredocs and YAML block scalars are kept as written, Markdown
+// keeps trailing spaces (hard line breaks), Python, YAML, Makefiles and
+// Markdown keep their indentation, and diffs are returned unchanged.
+func Compact(lang, src string, tabWidth int) string {
+	if lang == "diff" {
+		return src
+	}
+	trimTrailing := lang != "markdown"
+	switch lang {
+	case "python", "yaml", "makefile", "markdown":
+		tabWidth = 0
+	}
+
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	spans, _ := Lex(lang, src)
+	lit := literalCursor{spans: spans}
+
+	var sb strings.Builder
+	sb.Grow(len(src))
+	pendingBlank := false
+	for start := 0; start <= len(src); start = lineEnd(src, start) + 1 {
+		end := lineEnd(src, start)
+		line := src[start:end]
+		// A line is part of a literal when the literal began on an earlier
+		// line, or begins at its first byte and continues past it.
+		s, inLiteral := lit.at(start)
+		inLiteral = inLiteral && (s.Start < start || s.End > end)
+		_, endsInLiteral := lit.at(end)
+
+		if inLiteral {
+			if pendingBlank {
+				sb.WriteByte('\n')
+				pendingBlank = false
+			}
+			if s.End <= end && trimTrailing && !endsInLiteral {
+				// Code after the closing delimiter is trimmed as usual.
+				line = line[:s.End-start] + strings.TrimRight(line[s.End-start:], " \t")
+			}
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			pendingBlank = sb.Len() > 0
+			continue
+		}
+		if pendingBlank {
+			sb.WriteByte('\n')
+			pendingBlank = false
+		}
+		if trimTrailing && !endsInLiteral {
+			line = strings.TrimRight(line, " \t")
+		}
+		if tabWidth > 0 {
+			line = tabIndent(line, tabWidth)
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+
+	out := sb.String()
+	if !strings.HasSuffix(src, "\n") {
+		out = strings.TrimSuffix(out, "\n")
+	}
+	return out
+}
+
+// literalCursor finds the string spans that contain offsets queried in
+// increasing order.
+type literalCursor struct {
+	spans []Span
+	next  int
+}
+
+func (c *literalCursor) at(pos int) (Span, bool) {
+	for c.next < len(c.spans) && (c.spans[c.next].End <= pos || c.spans[c.next].Kind != SpanString) {
+		c.next++
+	}
+	if c.next < len(c.spans) && c.spans[c.next].Start <= pos {
+		return c.spans[c.next], true
+	}
+	return Span{}, false
+}
+
+// tabIndent rewrites the leading spaces and tabs of line as tabs of width
+// columns, followed by any remaining spaces.
+func tabIndent(line string, width int) string {
+	n, cols := 0, 0
+	for n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+		if line[n] == '\t' {
+			cols += width - cols%width
+		} else {
+			cols++
+		}
+		n++
+	}
+	if n == 0 {
+		return line
+	}
+	return strings.Repeat("\t", cols/width) + strings.Repeat(" ", cols%width) + line[n:]
+}
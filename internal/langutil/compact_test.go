@@ -0,0 +1,35 @@
+package langutil
+
+import "testing"
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		src      string
+		tabWidth int
+		want     string
+	}{
+		{"blank runs and trailing space", "go", "\n\npackage p  \n\n\n\nvar x int\t\n\n\n", 0, "package p\n\nvar x int\n"},
+		{"crlf", "go", "a\r\n\r\n\r\nb\r\n", 0, "a\n\nb\n"},
+		{"no final newline", "", "a  \n\n\nb  ", 0, "a\n\nb"},
+		{"tabs", "go", "func f() {\n        x := 1\n          y\n    \tz\n}\n", 4, "func f() {\n\t\tx := 1\n\t\t  y\n\t\tz\n}\n"},
+		{"python keeps indentation", "python", "def f():\n    return 1   \n", 4, "def f():\n    return 1\n"},
+		{"yaml keeps indentation", "yaml", "a:\n    b: 1\n", 2, "a:\n    b: 1\n"},
+		{"markdown keeps hard breaks", "markdown", "line one  \nline two\n\n\n\n    code\n", 4, "line one  \nline two\n\n    code\n"},
+		{"makefile keeps recipes", "makefile", "all:\n\tgo build  \n", 4, "all:\n\tgo build\n"},
+		{"yaml block scalar", "yaml", "a: |\n  line1\n\n\n    line2   \nb: 1  \n\n\nc: 2\n", 0, "a: |\n  line1\n\n\n    line2   \nb: 1\n\nc: 2\n"},
+		{"python triple-quoted string", "python", "x = \"\"\"a   \n\n\n\nb\"\"\"   \n\n\ny = 1\n", 0, "x = \"\"\"a   \n\n\n\nb\"\"\"\n\ny = 1\n"},
+		{"go raw string", "go", "var s = `\n    a  \n\n\n`  \n\n\nvar t = \"x\"  \n", 4, "var s = `\n    a  \n\n\n`\n\nvar t = \"x\"\n"},
+		{"shell heredoc", "bash", "cat <<EOF\nx  \n\n\nEOF\n\n\necho\n", 0, "cat <<EOF\nx  \n\n\nEOF\n\necho\n"},
+		{"diff unchanged", "diff", "@@ -1 +1 @@\n \n-a \n+b\n\n\n", 4, "@@ -1 +1 @@\n \n-a \n+b\n\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compact(tt.lang, tt.src, tt.tabWidth); got != tt.want {
+				t.Errorf("Compact(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
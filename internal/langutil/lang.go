@@ -0,0 +1,132 @@
+package langutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+var languagesByExtension = map[string]string{
+	".go":         "go",
+	".c":          "c",
+	".h":          "c",
+	".cc":         "cpp",
+	".cpp":        "cpp",
+	".cxx":        "cpp",
+	".hh":         "cpp",
+	".hpp":        "cpp",
+	".hxx":        "cpp",
+	".cs":         "csharp",
+	".java":       "java",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".scala":      "scala",
+	".swift":      "swift",
+	".m":          "objectivec",
+	".mm":         "objectivec",
+	".rs":         "rust",
+	".py":         "python",
+	".pyi":        "python",
+	".pyw":        "python",
+	".rb":         "ruby",
+	".php":        "php",
+	".pl":         "perl",
+	".pm":         "perl",
+	".lua":        "lua",
+	".r":          "r",
+	".dart":       "dart",
+	".ex":         "elixir",
+	".exs":        "elixir",
+	".erl":        "erlang",
+	".hs":         "haskell",
+	".clj":        "clojure",
+	".ml":         "ocaml",
+	".fs":         "fsharp",
+	".zig":        "zig",
+	".js":         "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".jsx":        "jsx",
+	".ts":         "typescript",
+	".mts":        "typescript",
+	".cts":        "typescript",
+	".tsx":        "tsx",
+	".vue":        "vue",
+	".svelte":     "svelte",
+	".html":       "html",
+	".htm":        "html",
+	".css":        "css",
+	".scss":       "scss",
+	".sass":       "sass",
+	".less":       "less",
+	".json":       "json",
+	".jsonc":      "jsonc",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".toml":       "toml",
+	".ini":        "ini",
+	".cfg":        "ini",
+	".xml":        "xml",
+	".svg":        "xml",
+	".md":         "markdown",
+	".mdx":        "markdown",
+	".rst":        "rst",
+	".tex":        "latex",
+	".sql":        "sql",
+	".sh":         "bash",
+	".bash":       "bash",
+	".zsh":        "zsh",
+	".fish":       "fish",
+	".ps1":        "powershell",
+	".bat":        "batch",
+	".cmd":        "batch",
+	".tf":         "hcl",
+	".hcl":        "hcl",
+	".proto":      "protobuf",
+	".graphql":    "graphql",
+	".gql":        "graphql",
+	".mk":         "makefile",
+	".cmake":      "cmake",
+	".gradle":     "groovy",
+	".groovy":     "groovy",
+	".dockerfile": "dockerfile",
+	".diff":       "diff",
+	".patch":      "diff",
+	".csv":        "csv",
+	".txt":        "text",
+}
+
+var languagesByName = map[string]string{
+	"makefile":       "makefile",
+	"gnumakefile":    "makefile",
+	"dockerfile":     "dockerfile",
+	"containerfile":  "dockerfile",
+	"cmakelists.txt": "cmake",
+	"gemfile":        "ruby",
+	"rakefile":       "ruby",
+	"vagrantfile":    "ruby",
+	"jenkinsfile":    "groovy",
+	"go.mod":         "gomod",
+	"go.sum":         "text",
+	".bashrc":        "bash",
+	".zshrc":         "zsh",
+	".profile":       "bash",
+	".gitignore":     "gitignore",
+	".dockerignore":  "gitignore",
+	".env":           "dotenv",
+}
+
+// Detect returns a short language identifier for path, suitable as a
+// Markdown code fence tag, or "" when the language is unknown.
+func Detect(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	if lang, ok := languagesByName[name]; ok {
+		return lang
+	}
+	if strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile") {
+		return "dockerfile"
+	}
+	if strings.HasPrefix(name, "makefile.") {
+		return "makefile"
+	}
+	return languagesByExtension[filepath.Ext(name)]
+}
@@ -0,0 +1,28 @@
+package langutil
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "go"},
+		{"src/app/Component.TSX", "tsx"},
+		{"scripts/build.sh", "bash"},
+		{"Makefile", "makefile"},
+		{"deploy/Dockerfile.prod", "dockerfile"},
+		{"config/settings.yml", "yaml"},
+		{"go.mod", "gomod"},
+		{"LICENSE", ""},
+		{"archive.unknownext", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := Detect(tt.path); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,729 @@
+package langutil
+
+import "strings"
+
+// SpanKind classifies a lexed region of source text.
+type SpanKind int
+
+const (
+	SpanComment SpanKind = iota
+	SpanString
+)
+
+// Span is a comment or string literal at byte offsets [Start, End) of the
+// source. Everything outside the spans is code.
+type Span struct {
+	Start, End int
+	Kind       SpanKind
+	// Doc marks documentation comments.
+	Doc bool
+	// Directive marks comments that tools act on, such as shebangs and
+	// //go: lines; they are never stripped.
+	Directive bool
+}
+
+// stringDelim describes one kind of string literal.
+type stringDelim struct {
+	open, close string
+	// escape is the escape character, or 0 when the literal has none.
+	escape byte
+	// doubled means a repeated close delimiter stands for itself ('it''s').
+	doubled bool
+	// multiline literals may contain newlines; others end at the line.
+	multiline bool
+}
+
+type syntax struct {
+	line    []string
+	block   [][2]string
+	nested  bool
+	strings []stringDelim
+
+	// hashAtWordStart: "#" only starts a comment at the start of a word.
+	hashAtWordStart bool
+	// docPrefixes are comment openings that mark documentation, such as
+	// "///" or "/**". A prefix followed by its own last character (e.g.
+	// "////") is not a doc comment.
+	docPrefixes []string
+	// docBeforeCode: whole-line comments directly above code are doc
+	// comments, as in Go.
+	docBeforeCode bool
+	directives    []string
+
+	// Language-specific literal forms.
+	jsRegex      bool
+	rustLiterals bool
+	cppRaw       bool
+	csVerbatim   bool
+	shellHeredoc bool
+	sqlDollar    bool
+	yaml         bool
+}
+
+var (
+	goSyntax = &syntax{
+		line:  []string{"//"},
+		block: [][2]string{{"/*", "*/"}},
+		strings: []stringDelim{
+			{open: "`", close: "`", multiline: true},
+			{open: `"`, close: `"`, escape: '\\'},
+			{open: "'", close: "'", escape: '\\'},
+		},
+		docBeforeCode: true,
+		directives:    []string{"//go:", "// +build", "//line ", "//export "},
+	}
+	cSyntax = &syntax{
+		line:  []string{"//"},
+		block: [][2]string{{"/*", "*/"}},
+		strings: []stringDelim{
+			{open: `"`, close: `"`, escape: '\\'},
+			{open: "'", close: "'", escape: '\\'},
+		},
+		docPrefixes: []string{"/**", "///", "/*!", "//!"},
+	}
+	cppSyntax = withOptions(cSyntax, func(s *syntax) { s.cppRaw = true })
+	csSyntax  = withOptions(cSyntax, func(s *syntax) { s.csVerbatim = true })
+	jvmSyntax = withOptions(cSyntax, func(s *syntax) {
+		s.strings = append([]stringDelim{{open: `"""`, close: `"""`, escape: '\\', multiline: true}}, s.strings...)
+	})
+	jsSyntax = &syntax{
+		line:  []string{"//"},
+		block: [][2]string{{"/*", "*/"}},
+		strings: []stringDelim{
+			{open: "`", close: "`", escape: '\\', multiline: true},
+			{open: `"`, close: `"`, escape: '\\'},
+			{open: "'", close: "'", escape: '\\'},
+		},
+		docPrefixes: []string{"/**"},
+		directives:  []string{"/// <reference", "// @ts-", "//# sourceMappingURL", "/*!", "// eslint-", "/* eslint-"},
+		jsRegex:     true,
+	}
+	rustSyntax = &syntax{
+		line:         []string{"//"},
+		block:        [][2]string{{"/*", "*/"}},
+		nested:       true,
+		strings:      []stringDelim{{open: `"`, close: `"`, escape: '\\', multiline: true}},
+		docPrefixes:  []string{"///", "//!", "/**", "/*!"},
+		rustLiterals: true,
+	}
+	pythonSyntax = &syntax{
+		line: []string{"#"},
+		strings: []stringDelim{
+			{open: `"""`, close: `"""`, escape: '\\', multiline: true},
+			{open: "'''", close: "'''", escape: '\\', multiline: true},
+			{open: `"`, close: `"`, escape: '\\'},
+			{open: "'", close: "'", escape: '\\'},
+		},
+		directives: []string{"# -*- coding", "# coding:", "# coding=", "# type:", "# noqa", "# pragma"},
+	}
+	shellSyntax = &syntax{
+		line: []string{"#"},
+		strings: []stringDelim{
+			{open: "$'", close: "'", escape: '\\', multiline: true},
+			{open: "'", close: "'", multiline: true},
+			{open: `"`, close: `"`, escape: '\\', multiline: true},
+		},
+		hashAtWordStart: true,
+		shellHeredoc:    true,
+	}
+	sqlSyntax = &syntax{
+		line:  []string{"--"},
+		block: [][2]string{{"/*", "*/"}},
+		strings: []stringDelim{
+			{open: "'", close: "'", doubled: true, multiline: true},
+			{open: `"`, close: `"`, doubled: true, multiline: true},
+		},
+		sqlDollar: true,
+	}
+	yamlSyntax = &syntax{
+		line: []string{"#"},
+		strings: []stringDelim{
+			{open: "'", close: "'", doubled: true, multiline: true},
+			{open: `"`, close: `"`, escape: '\\', multiline: true},
+		},
+		hashAtWordStart: true,
+		yaml:            true,
+	}
+)
+
+func withOptions(base *syntax, apply func(*syntax)) *syntax {
+	s := *base
+	apply(&s)
+	return &s
+}
+
+var syntaxByLanguage = map[string]*syntax{
+	"go":         goSyntax,
+	"c":          cSyntax,
+	"objectivec": cSyntax,
+	"cpp":        cppSyntax,
+	"csharp":     csSyntax,
+	"java":       jvmSyntax,
+	"kotlin":     jvmSyntax,
+	"scala":      jvmSyntax,
+	"swift":      jvmSyntax,
+	"dart":       jvmSyntax,
+	"groovy":     jvmSyntax,
+	"javascript": jsSyntax,
+	"jsx":        jsSyntax,
+	"typescript": jsSyntax,
+	"tsx":        jsSyntax,
+	"rust":       rustSyntax,
+	"python":     pythonSyntax,
+	"bash":       shellSyntax,
+	"zsh":        shellSyntax,
+	"sql":        sqlSyntax,
+	"yaml":       yamlSyntax,
+}
+
+// SupportsComments reports whether Lex knows the comment syntax of lang.
+func SupportsComments(lang string) bool {
+	_, ok := syntaxByLanguage[lang]
+	return ok
+}
+
+// Lex finds the comments and string literals of src, written in lang, in
+// source order. ok is false when lang is not supported.
+func Lex(lang, src string) (spans []Span, ok bool) {
+	syn, ok := syntaxByLanguage[lang]
+	if !ok {
+		return nil, false
+	}
+	l := &lexer{syn: syn, src: src, yamlBlockIndent: -1}
+	l.run()
+	if syn.docBeforeCode {
+		l.markDocsBeforeCode()
+	}
+	return l.spans, true
+}
+
+type lexer struct {
+	syn   *syntax
+	src   string
+	spans []Span
+
+	// pendingHeredocs are shell here-document terminators that take effect
+	// at the next newline.
+	pendingHeredocs []heredoc
+	// yamlBlockIndent is the indentation of the key that opened a YAML block
+	// scalar, or -1 outside block scalars.
+	yamlBlockIndent int
+}
+
+type heredoc struct {
+	word      string
+	stripTabs bool
+}
+
+func (l *lexer) run() {
+	src := l.src
+	i := 0
+	if strings.HasPrefix(src, "#!") && l.syn.line != nil && l.syn.line[0] == "#" {
+		end := lineEnd(src, 0)
+		l.spans = append(l.spans, Span{Start: 0, End: end, Kind: SpanComment, Directive: true})
+		i = end
+	}
+
+	for i < len(src) {
+		c := src[i]
+
+		if c == '\n' {
+			i++
+			if len(l.pendingHeredocs) > 0 {
+				i = l.skipHeredocs(i)
+			}
+			if l.syn.yaml {
+				i = l.skipYAMLBlockScalar(i)
+			}
+			continue
+		}
+
+		if end, ok := l.comment(i); ok {
+			i = end
+			continue
+		}
+		if end, ok := l.literal(i); ok {
+			l.spans = append(l.spans, Span{Start: i, End: end, Kind: SpanString})
+			i = end
+			continue
+		}
+		if l.syn.shellHeredoc && strings.HasPrefix(src[i:], "<<") && !strings.HasPrefix(src[i:], "<<<") {
+			i = l.heredocStart(i)
+			continue
+		}
+		if l.syn.yaml && (c == '|' || c == '>') {
+			l.yamlBlockStart(i)
+		}
+		i++
+	}
+}
+
+// comment reports whether a comment starts at i and records it.
+func (l *lexer) comment(i int) (int, bool) {
+	src := l.src
+	for _, marker := range l.syn.line {
+		if !strings.HasPrefix(src[i:], marker) {
+			continue
+		}
+		if marker == "#" && l.syn.hashAtWordStart && i > 0 && !isWordBoundary(src[i-1]) {
+			continue
+		}
+		end := lineEnd(src, i)
+		l.addComment(i, end)
+		return end, true
+	}
+	for _, pair := range l.syn.block {
+		if !strings.HasPrefix(src[i:], pair[0]) {
+			continue
+		}
+		end := l.blockEnd(i, pair)
+		l.addComment(i, end)
+		return end, true
+	}
+	return 0, false
+}
+
+func (l *lexer) blockEnd(i int, pair [2]string) int {
+	src := l.src
+	depth := 0
+	for j := i; j < len(src); {
+		switch {
+		case strings.HasPrefix(src[j:], pair[0]) && (depth == 0 || l.syn.nested):
+			depth++
+			j += len(pair[0])
+		case strings.HasPrefix(src[j:], pair[1]):
+			depth--
+			j += len(pair[1])
+			if depth == 0 {
+				return j
+			}
+		default:
+			j++
+		}
+	}
+	return len(src)
+}
+
+func (l *lexer) addComment(start, end int) {
+	text := l.src[start:end]
+	span := Span{Start: start, End: end, Kind: SpanComment}
+	for _, d := range l.syn.directives {
+		if strings.HasPrefix(text, d) {
+			span.Directive = true
+		}
+	}
+	for _, p := range l.syn.docPrefixes {
+		if strings.HasPrefix(text, p) && !strings.HasPrefix(text, p+p[len(p)-1:]) && text != "/**/" {
+			span.Doc = true
+		}
+	}
+	l.spans = append(l.spans, span)
+}
+
+// literal reports whether a string, character or regular expression literal
+// starts at i and returns its end.
+func (l *lexer) literal(i int) (int, bool) {
+	src := l.src
+	syn := l.syn
+
+	if syn.rustLiterals {
+		if end, ok := rustRawString(src, i); ok {
+			return end, true
+		}
+		if src[i] == '\'' {
+			return rustChar(src, i)
+		}
+	}
+	if syn.cppRaw && strings.HasPrefix(src[i:], `R"`) && (i == 0 || !isIdentByte(src[i-1]) || strings.ContainsRune("8uUL", rune(src[i-1]))) {
+		if end, ok := cppRawString(src, i); ok {
+			return end, true
+		}
+	}
+	if syn.csVerbatim && strings.HasPrefix(src[i:], `@"`) {
+		return scanString(src, i+1, stringDelim{open: `"`, close: `"`, doubled: true, multiline: true}), true
+	}
+	if syn.sqlDollar && src[i] == '$' {
+		if end, ok := dollarQuoted(src, i); ok {
+			return end, true
+		}
+	}
+	if syn.jsRegex && src[i] == '/' && regexAllowed(src, i) {
+		if end, ok := jsRegex(src, i); ok {
+			return end, true
+		}
+	}
+	if syn.yaml && (src[i] == '\'' || src[i] == '"') && !yamlScalarStart(src, i) {
+		return 0, false
+	}
+
+	for _, d := range syn.strings {
+		if strings.HasPrefix(src[i:], d.open) {
+			return scanString(src, i, d), true
+		}
+	}
+	return 0, false
+}
+
+func scanString(src string, i int, d stringDelim) int {
+	j := i + len(d.open)
+	for j < len(src) {
+		if d.escape != 0 && src[j] == d.escape {
+			j += 2
+			continue
+		}
+		if strings.HasPrefix(src[j:], d.close) {
+			if d.doubled && strings.HasPrefix(src[j+len(d.close):], d.close) {
+				j += 2 * len(d.close)
+				continue
+			}
+			return j + len(d.close)
+		}
+		if src[j] == '\n' && !d.multiline {
+			return j
+		}
+		j++
+	}
+	return len(src)
+}
+
+// rustRawString matches r"..", r#".."#, br"..", and so on.
+func rustRawString(src string, i int) (int, bool) {
+	if i > 0 && isIdentByte(src[i-1]) {
+		return 0, false
+	}
+	j := i
+	if j < len(src) && src[j] == 'b' {
+		j++
+	}
+	if j >= len(src) || src[j] != 'r' {
+		return 0, false
+	}
+	j++
+	hashes := 0
+	for j < len(src) && src[j] == '#' {
+		hashes++
+		j++
+	}
+	if j >= len(src) || src[j] != '"' {
+		return 0, false
+	}
+	closing := `"` + strings.Repeat("#", hashes)
+	if end := strings.Index(src[j+1:], closing); end >= 0 {
+		return j + 1 + end + len(closing), true
+	}
+	return len(src), true
+}
+
+// rustChar distinguishes character literals from lifetimes such as 'a.
+func rustChar(src string, i int) (int, bool) {
+	if i+1 < len(src) && src[i+1] == '\\' {
+		return scanString(src, i, stringDelim{open: "'", close: "'", escape: '\\'}), true
+	}
+	// A single (possibly multi-byte) character followed by a quote.
+	for j := i + 2; j < len(src) && j <= i+5; j++ {
+		if src[j] == '\'' {
+			return j + 1, true
+		}
+		if src[j] < 0x80 {
+			break
+		}
+	}
+	return 0, false
+}
+
+// cppRawString matches R"delim( ... )delim".
+func cppRawString(src string, i int) (int, bool) {
+	open := strings.IndexByte(src[i+2:], '(')
+	if open < 0 || open > 16 {
+		return 0, false
+	}
+	delim := src[i+2 : i+2+open]
+	if strings.ContainsAny(delim, " \\)\n\t") {
+		return 0, false
+	}
+	closing := ")" + delim + `"`
+	body := i + 2 + open + 1
+	if end := strings.Index(src[body:], closing); end >= 0 {
+		return body + end + len(closing), true
+	}
+	return len(src), true
+}
+
+// dollarQuoted matches PostgreSQL $tag$ ... $tag$ strings.
+func dollarQuoted(src string, i int) (int, bool) {
+	j := i + 1
+	for j < len(src) && isIdentByte(src[j]) && !(src[j] >= '0' && src[j] <= '9' && j == i+1) {
+		j++
+	}
+	if j >= len(src) || src[j] != '$' {
+		return 0, false
+	}
+	tag := src[i : j+1]
+	if end := strings.Index(src[j+1:], tag); end >= 0 {
+		return j + 1 + end + len(tag), true
+	}
+	return len(src), true
+}
+
+// regexAllowed reports whether a "/" at i starts a regular expression rather
+// than a division, judging by the previous significant character.
+func regexAllowed(src string, i int) bool {
+	j := i - 1
+	for j >= 0 && (src[j] == ' ' || src[j] == '\t') {
+		j--
+	}
+	if j < 0 {
+		return true
+	}
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^\n", src[j]) >= 0 {
+		return true
+	}
+	for _, kw := range []string{"return", "typeof", "case", "in", "of", "void", "throw", "yield", "await"} {
+		if strings.HasSuffix(src[:j+1], kw) && (j+1-len(kw) == 0 || !isIdentByte(src[j-len(kw)])) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsRegex(src string, i int) (int, bool) {
+	inClass := false
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(src) && isIdentByte(src[j]) {
+					j++
+				}
+				return j, true
+			}
+		case '\n':
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// heredocStart records the terminator of a shell here-document beginning at
+// i and returns the offset after the operator and word.
+func (l *lexer) heredocStart(i int) int {
+	src := l.src
+	j := i + 2
+	h := heredoc{}
+	if j < len(src) && src[j] == '-' {
+		h.stripTabs = true
+		j++
+	}
+	for j < len(src) && (src[j] == ' ' || src[j] == '\t') {
+		j++
+	}
+	start := j
+	for j < len(src) && !isWordBoundary(src[j]) && src[j] != ';' && src[j] != '|' && src[j] != '&' && src[j] != '<' && src[j] != '>' {
+		j++
+	}
+	h.word = strings.Trim(src[start:j], `'"\`)
+	if h.word != "" && (h.word[0] == '_' || isIdentByte(h.word[0]) && !(h.word[0] >= '0' && h.word[0] <= '9')) {
+		l.pendingHeredocs = append(l.pendingHeredocs, h)
+	}
+	return j
+}
+
+// skipHeredocs skips the bodies of pending here-documents starting at the
+// line beginning at i, recording them as strings.
+func (l *lexer) skipHeredocs(i int) int {
+	src := l.src
+	for _, h := range l.pendingHeredocs {
+		start := i
+		for i < len(src) {
+			end := lineEnd(src, i)
+			line := src[i:end]
+			if h.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			i = min(end+1, len(src))
+			if strings.TrimSuffix(line, "\r") == h.word {
+				break
+			}
+		}
+		l.spans = append(l.spans, Span{Start: start, End: i, Kind: SpanString})
+	}
+	l.pendingHeredocs = nil
+	return i
+}
+
+// yamlBlockStart notes a "|" or ">" block scalar indicator that ends its line
+// (apart from a comment) after a key or sequence marker.
+func (l *lexer) yamlBlockStart(i int) {
+	src := l.src
+	ls := lineStart(src, i)
+	before := strings.TrimRight(src[ls:i], " \t")
+	if !strings.HasSuffix(before, ":") && !strings.HasSuffix(before, "-") && before != "" {
+		return
+	}
+	j := i + 1
+	for j < len(src) && strings.IndexByte("+-0123456789", src[j]) >= 0 {
+		j++
+	}
+	rest := strings.TrimSpace(src[j:lineEnd(src, j)])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return
+	}
+	l.yamlBlockIndent = indentOf(src[ls:lineEnd(src, ls)])
+}
+
+// skipYAMLBlockScalar skips the lines of a pending block scalar starting at
+// line offset i: blank lines and lines indented deeper than its key.
+func (l *lexer) skipYAMLBlockScalar(i int) int {
+	if l.yamlBlockIndent < 0 {
+		return i
+	}
+	src := l.src
+	start := i
+	for i < len(src) {
+		end := lineEnd(src, i)
+		line := src[i:end]
+		if strings.TrimSpace(line) != "" && indentOf(line) <= l.yamlBlockIndent {
+			break
+		}
+		i = min(end+1, len(src))
+	}
+	l.yamlBlockIndent = -1
+	if i > start {
+		l.spans = append(l.spans, Span{Start: start, End: i, Kind: SpanString})
+	}
+	return i
+}
+
+// yamlScalarStart reports whether a quote at i opens a quoted scalar rather
+// than appearing inside a plain one (as in "don't").
+func yamlScalarStart(src string, i int) bool {
+	j := i - 1
+	for j >= 0 && (src[j] == ' ' || src[j] == '\t') {
+		j--
+	}
+	return j < 0 || strings.IndexByte(":-,[{?\n", src[j]) >= 0
+}
+
+// markDocsBeforeCode marks groups of whole-line comments that are directly
+// followed by a declaration: any line of code at the top level, or a line
+// that looks like a field inside braces. Comments in function bodies are
+// therefore not doc comments.
+func (l *lexer) markDocsBeforeCode() {
+	src := l.src
+	depths := l.braceDepths()
+	for i := 0; i < len(l.spans); {
+		s := l.spans[i]
+		if s.Kind != SpanComment || !l.isWholeLine(s) {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < len(l.spans) && l.spans[j+1].Kind == SpanComment && l.isWholeLine(l.spans[j+1]) &&
+			strings.Count(src[l.spans[j].End:l.spans[j+1].Start], "\n") == 1 &&
+			strings.TrimSpace(src[l.spans[j].End:l.spans[j+1].Start]) == "" {
+			j++
+		}
+
+		next := lineEnd(src, l.spans[j].End) + 1
+		doc := false
+		if next < len(src) {
+			line := src[next:lineEnd(src, next)]
+			codeStart := next + indentOf(line)
+			startsWithComment := j+1 < len(l.spans) && l.spans[j+1].Kind == SpanComment && l.spans[j+1].Start == codeStart
+			doc = strings.TrimSpace(line) != "" && !startsWithComment &&
+				(depths[i] == 0 || looksLikeField(line))
+		}
+		for k := i; k <= j; k++ {
+			l.spans[k].Doc = doc
+		}
+		i = j + 1
+	}
+}
+
+// braceDepths returns the curly brace nesting depth at the start of each
+// span, counting only braces outside comments and strings.
+func (l *lexer) braceDepths() []int {
+	depths := make([]int, len(l.spans))
+	depth, pos := 0, 0
+	for i, s := range l.spans {
+		depth += strings.Count(l.src[pos:s.Start], "{") - strings.Count(l.src[pos:s.Start], "}")
+		depths[i] = depth
+		pos = s.End
+	}
+	return depths
+}
+
+// looksLikeField reports whether line starts like a struct field or an
+// embedded type rather than a statement: an identifier that is not a
+// keyword, followed by a type or by nothing but a struct tag.
+func looksLikeField(line string) bool {
+	line = strings.TrimSpace(line)
+	if tag := strings.IndexByte(line, '`'); tag >= 0 {
+		line = strings.TrimSpace(line[:tag])
+	}
+	if strings.Contains(line, "=") {
+		return false
+	}
+	line = strings.TrimPrefix(line, "*")
+	n := 0
+	for n < len(line) && (isIdentByte(line[n]) || line[n] == '.') {
+		n++
+	}
+	if n == 0 {
+		return false
+	}
+	switch line[:n] {
+	case "break", "case", "continue", "default", "defer", "else", "fallthrough",
+		"for", "go", "goto", "if", "return", "select", "switch", "var", "const", "type":
+		return false
+	}
+	if n == len(line) {
+		return true
+	}
+	if c := line[n]; c != ' ' && c != '\t' {
+		return false
+	}
+	rest := strings.TrimLeft(line[n:], " \t")
+	return isIdentByte(rest[0]) || rest[0] == '*' || rest[0] == '['
+}
+
+// isWholeLine reports whether span s is alone on its lines.
+func (l *lexer) isWholeLine(s Span) bool {
+	src := l.src
+	return strings.TrimSpace(src[lineStart(src, s.Start):s.Start]) == "" &&
+		strings.TrimSpace(src[s.End:lineEnd(src, s.End)]) == ""
+}
+
+func lineStart(src string, i int) int {
+	return strings.LastIndexByte(src[:i], '\n') + 1
+}
+
+func lineEnd(src string, i int) int {
+	if n := strings.IndexByte(src[i:], '\n'); n >= 0 {
+		return i + n
+	}
+	return len(src)
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isWordBoundary(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', ';', '|', '&', '(', ')':
+		return true
+	}
+	return false
+}
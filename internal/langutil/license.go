@@ -0,0 +1,96 @@
+package langutil
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// licenseMarker matches text that identifies a comment as a license or
+	// copyright notice.
+	licenseMarker = regexp.MustCompile(`(?i)SPDX-License-Identifier|copyright|\(c\)\s*\d|©|all rights reserved|licensed under|apache license|mit license|permission is hereby granted|gnu (?:lesser |affero )?general public license|mozilla public license|bsd license|redistribution and use in source and binary forms`)
+
+	commentLinePrefix = regexp.MustCompile(`^\s*(?:/\*+!?|\*+/|\*|//+!?|#+|--+|;+|%+|<!--|\{-|\(\*)?\s?`)
+	commentLineSuffix = regexp.MustCompile(`\s*(?:\*+/|-->|-\}|\*\))\s*$`)
+	licenseYears      = regexp.MustCompile(`\b(?:19|20)\d{2}(?:\s*[-–,]\s*(?:(?:19|20)\d{2}|present))*\b`)
+)
+
+// StripLicenseHeader removes the license header of src, written in lang: the
+// first comment block at the top of the file that contains a license marker
+// such as an SPDX identifier or a copyright line, or that contains template.
+// Only comments may precede the header; shebangs and other directives are
+// kept. header is the removed block as returned by NormalizeComment. ok is
+// false when lang is not supported or no header was found; src is then
+// returned unchanged.
+//
+// template is compared after normalization, with years ignored, so it may be
+// written with or without comment markers.
+func StripLicenseHeader(lang, src, template string) (out, header string, ok bool) {
+	spans, ok := Lex(lang, src)
+	if !ok {
+		return src, "", false
+	}
+	template = licenseYears.ReplaceAllString(NormalizeComment(template), "YEAR")
+
+	for i := 0; i < len(spans); {
+		s := spans[i]
+		if s.Kind != SpanComment || strings.TrimSpace(src[prevEnd(spans, i):s.Start]) != "" {
+			break
+		}
+		// A block runs over comments separated by at most one newline.
+		j := i + 1
+		for j < len(spans) && spans[j].Kind == SpanComment && !spans[j].Directive &&
+			strings.TrimSpace(src[spans[j-1].End:spans[j].Start]) == "" &&
+			strings.Count(src[spans[j-1].End:spans[j].Start], "\n") <= 1 {
+			j++
+		}
+		start, end := s.Start, spans[j-1].End
+		if s.Directive {
+			i++
+			continue
+		}
+
+		text := NormalizeComment(src[start:end])
+		if licenseMarker.MatchString(text) ||
+			template != "" && strings.Contains(licenseYears.ReplaceAllString(text, "YEAR"), template) {
+			return removeBlock(src, start, end), text, true
+		}
+		i = j
+	}
+	return src, "", false
+}
+
+// prevEnd returns the end of the span before spans[i], or 0.
+func prevEnd(spans []Span, i int) int {
+	if i == 0 {
+		return 0
+	}
+	return spans[i-1].End
+}
+
+// removeBlock cuts [start, end) out of src together with the rest of its
+// last line and the blank lines that follow it.
+func removeBlock(src string, start, end int) string {
+	for end < len(src) {
+		le := lineEnd(src, end)
+		if strings.TrimSpace(src[end:le]) != "" {
+			break
+		}
+		end = le
+		if end < len(src) {
+			end++
+		}
+	}
+	return src[:start] + src[end:]
+}
+
+// NormalizeComment returns the text of a comment block without comment
+// markers, with runs of whitespace collapsed to single spaces.
+func NormalizeComment(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = commentLineSuffix.ReplaceAllString(line, "")
+		lines[i] = commentLinePrefix.ReplaceAllString(line, "")
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, "\n")), " ")
+}
@@ -0,0 +1,73 @@
+package langutil
+
+import "testing"
+
+func TestStripLicenseHeader(t *testing.T) {
+	const apache = "// Copyright 2024 Acme Corp.\n//\n// Licensed under the Apache License, Version 2.0.\n"
+	tests := []struct {
+		name       string
+		lang       string
+		src        string
+		template   string
+		want       string
+		wantHeader string
+	}{
+		{
+			name:       "go line comments",
+			lang:       "go",
+			src:        apache + "\n// Package p does things.\npackage p\n",
+			want:       "// Package p does things.\npackage p\n",
+			wantHeader: "Copyright 2024 Acme Corp. Licensed under the Apache License, Version 2.0.",
+		},
+		{
+			name:       "block comment",
+			lang:       "java",
+			src:        "/*\n * SPDX-License-Identifier: MIT\n */\npackage p;\n",
+			want:       "package p;\n",
+			wantHeader: "SPDX-License-Identifier: MIT",
+		},
+		{
+			name:       "shebang kept",
+			lang:       "python",
+			src:        "#!/usr/bin/env python3\n# Copyright (c) 2023 Acme\n\nimport os\n",
+			want:       "#!/usr/bin/env python3\nimport os\n",
+			wantHeader: "Copyright (c) 2023 Acme",
+		},
+		{
+			name:       "second leading block",
+			lang:       "go",
+			src:        "//go:build linux\n\n// Generated file.\n\n/* Copyright 2020 Acme */\npackage p\n",
+			want:       "//go:build linux\n\n// Generated file.\n\npackage p\n",
+			wantHeader: "Copyright 2020 Acme",
+		},
+		{
+			name:       "template ignores years",
+			lang:       "python",
+			src:        "# Property of Acme, 2019-2024.\n# Do not distribute.\nx = 1\n",
+			template:   "Property of Acme, 2025.\nDo not distribute.\n",
+			want:       "x = 1\n",
+			wantHeader: "Property of Acme, 2019-2024. Do not distribute.",
+		},
+		{
+			name: "comment after code",
+			lang: "go",
+			src:  "package p\n\n// Copyright 2024 Acme\n",
+			want: "package p\n\n// Copyright 2024 Acme\n",
+		},
+		{
+			name: "no marker",
+			lang: "go",
+			src:  "// Package p does things.\npackage p\n",
+			want: "// Package p does things.\npackage p\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, header, ok := StripLicenseHeader(tt.lang, tt.src, tt.template)
+			if got != tt.want || header != tt.wantHeader || ok != (tt.wantHeader != "") {
+				t.Errorf("StripLicenseHeader() = %q, %q, %v; want %q, %q", got, header, ok, tt.want, tt.wantHeader)
+			}
+		})
+	}
+}
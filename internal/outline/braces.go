@@ -0,0 +1,476 @@
+package outline
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+)
+
+// braceFamily describes the declarations of a brace-delimited language.
+type braceFamily struct {
+	// containers are outlined member by member: class, impl, namespace.
+	containers map[string]bool
+	// types are kept in full: struct, interface, enum.
+	types map[string]bool
+	// functions have their bodies replaced by "{ ... }".
+	functions map[string]bool
+	// imports are the lead words of top-level statements that are kept,
+	// such as import, package and use.
+	imports map[string]bool
+	// arrows: a header ending in "=>" starts a function body.
+	arrows bool
+	// asi: a newline can end a statement, as in JavaScript.
+	asi bool
+}
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	jsFamily = &braceFamily{
+		containers: wordSet("class", "namespace", "module"),
+		types:      wordSet("interface", "enum", "type"),
+		functions:  wordSet("function"),
+		imports:    wordSet("import", "export", "declare", "type", "function"),
+		arrows:     true,
+		asi:        true,
+	}
+	javaFamily = &braceFamily{
+		containers: wordSet("class", "interface", "enum", "record"),
+		types:      wordSet(),
+		functions:  wordSet(),
+		imports:    wordSet("package", "import"),
+	}
+	rustFamily = &braceFamily{
+		containers: wordSet("impl", "trait", "mod"),
+		types:      wordSet("struct", "enum", "union"),
+		functions:  wordSet("fn"),
+		imports:    wordSet("use", "mod", "extern", "type", "struct"),
+	}
+)
+
+// modifiers are skipped when looking for the word that starts a statement.
+var modifiers = wordSet("export", "default", "public", "private", "protected", "internal",
+	"static", "final", "abstract", "async", "pub", "unsafe", "sealed", "readonly",
+	"override", "native", "synchronized", "strictfp", "transient", "volatile")
+
+// controlWords start statements that are never declarations.
+var controlWords = wordSet("if", "else", "for", "while", "do", "switch", "try", "catch",
+	"finally", "with", "return", "throw", "new", "match", "loop")
+
+type declKind int
+
+const (
+	declOther declKind = iota
+	declContainer
+	declType
+	declFunction
+)
+
+// braceOutline returns an outliner for a brace-delimited language. It keeps
+// imports, type declarations and the headers of classes, traits and impl
+// blocks, whose members are outlined in turn, and replaces function bodies
+// with "{ ... }". Doc comments directly above a kept declaration are kept.
+// Unbalanced braces make the outline fail.
+func braceOutline(lang string, fam *braceFamily) func(string) (string, bool) {
+	return func(src string) (string, bool) {
+		spans, ok := langutil.Lex(lang, src)
+		if !ok {
+			return src, false
+		}
+		o := &braceOutliner{fam: fam, src: src, code: maskCode(src, spans), spans: spans, unit: indentUnit(src)}
+		o.w.src = src
+		if end := o.block(0, false); end != len(src) || o.failed {
+			return src, false
+		}
+		return o.w.sb.String(), true
+	}
+}
+
+type braceOutliner struct {
+	fam    *braceFamily
+	src    string
+	code   []byte
+	spans  []langutil.Span
+	w      outlineWriter
+	failed bool
+	// unit is one level of indentation; indent is the indentation of the
+	// members of the container being outlined.
+	unit   string
+	indent string
+}
+
+// block outlines the statements from i up to the closing brace of the
+// enclosing block, or the end of the file, and returns that position.
+func (o *braceOutliner) block(i int, container bool) int {
+	for {
+		for i < len(o.code) && isSpaceByte(o.code[i]) {
+			i++
+		}
+		if i >= len(o.code) || o.code[i] == '}' || o.failed {
+			return i
+		}
+		i = o.statement(i, container)
+	}
+}
+
+// statement outlines the statement starting at start and returns the
+// position after it.
+func (o *braceOutliner) statement(start int, container bool) int {
+	code := o.code
+	inlineBraces := o.hasInlineBraces(start)
+	keepWhole := false
+	depth := 0
+	for i := start; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			if depth > 0 {
+				depth--
+			}
+		case c == '{' && (depth > 0 || inlineBraces):
+			close := o.matchBrace(i)
+			if close < 0 {
+				return len(code)
+			}
+			i = close
+		case depth > 0:
+		case c == ';':
+			o.simple(start, i+1, container, keepWhole)
+			return i + 1
+		case c == '}':
+			o.simple(start, i, container, keepWhole)
+			return i
+		case c == '\n' && o.fam.asi && o.endsAtNewline(start, i):
+			o.simple(start, i, container, keepWhole)
+			return i + 1
+		case c == '{':
+			close := o.matchBrace(i)
+			if close < 0 {
+				return len(code)
+			}
+			kind, kw := o.classify(start, i, container)
+			switch kind {
+			case declFunction:
+				end := o.tail(close + 1)
+				header := strings.TrimRight(o.src[start:i], " \t\r\n")
+				o.emit(start, end, header+" { ... }"+o.src[close+1:end])
+				return end
+			case declContainer:
+				indent := o.indentAt(start)
+				o.emit(start, i+1, o.src[start:i+1])
+				outer := o.indent
+				o.indent = indent + o.unit
+				o.block(i+1, true)
+				o.indent = outer
+				end := o.tail(close + 1)
+				o.w.sb.WriteString(indent + "}" + o.src[close+1:end] + "\n")
+				o.w.last = end
+				return end
+			case declType:
+				if kw == "type" {
+					// A type alias may continue after its object type.
+					keepWhole = true
+					i = close
+					continue
+				}
+				end := o.tail(close + 1)
+				o.emit(start, end, o.src[start:end])
+				return end
+			}
+			if !continuesAfterBlock(code[start:i]) {
+				return close + 1
+			}
+			i = close
+		}
+	}
+	o.simple(start, len(code), container, keepWhole)
+	return len(code)
+}
+
+// simple handles a statement without a body of its own. Container members
+// are kept as written; at the top level only imports, exports and type
+// aliases are.
+func (o *braceOutliner) simple(start, end int, container, keep bool) {
+	keep = keep || container
+	if !keep {
+		lead := o.leadWord(start)
+		text := string(o.code[start:end])
+		switch {
+		case o.fam.imports[lead]:
+			keep = true
+		case o.fam.asi && strings.HasPrefix(text, "export"):
+			keep = lead != "const" && lead != "let" && lead != "var"
+		case o.fam.asi && strings.Contains(text, "require("):
+			keep = true
+		}
+	}
+	if keep {
+		o.emit(start, end, strings.TrimRight(o.src[start:end], " \t\r\n"))
+	}
+}
+
+// emit writes a kept declaration together with the doc comments above it.
+func (o *braceOutliner) emit(start, end int, text string) {
+	docStart := o.docStart(start)
+	o.w.item(docStart, end, o.indentAt(docStart)+o.src[docStart:start]+text)
+}
+
+// indentAt returns the indentation of a declaration starting at pos: that
+// of its line when it starts the line, or the member indentation of the
+// enclosing container when it follows other code, as in
+// "impl S { fn a() {} }".
+func (o *braceOutliner) indentAt(pos int) string {
+	indent := lineIndent(o.src, pos)
+	if strings.LastIndexByte(o.src[:pos], '\n')+1+len(indent) == pos {
+		return indent
+	}
+	return o.indent
+}
+
+// indentUnit guesses one level of indentation from the first indented line
+// of src that is not inside a block comment, defaulting to four spaces.
+func indentUnit(src string) string {
+	for _, line := range strings.Split(src, "\n") {
+		indent := lineIndent(line, 0)
+		if rest := strings.TrimSpace(line); indent != "" && rest != "" && !strings.HasPrefix(rest, "*") {
+			return indent
+		}
+	}
+	return "    "
+}
+
+// docStart returns the start of the doc comments directly above pos, or
+// pos when there are none.
+func (o *braceOutliner) docStart(pos int) int {
+	k := sort.Search(len(o.spans), func(k int) bool { return o.spans[k].Start >= pos }) - 1
+	for ; k >= 0; k-- {
+		s := o.spans[k]
+		if s.Kind != langutil.SpanComment || !s.Doc || strings.TrimSpace(o.src[s.End:pos]) != "" {
+			break
+		}
+		pos = s.Start
+	}
+	return pos
+}
+
+// classify decides what the header code[start:brace] introduces and
+// returns the keyword that decided it.
+func (o *braceOutliner) classify(start, brace int, container bool) (declKind, string) {
+	kw := o.keyword(start, brace)
+	switch {
+	case o.fam.containers[kw]:
+		return declContainer, kw
+	case o.fam.types[kw]:
+		return declType, kw
+	case o.fam.functions[kw]:
+		return declFunction, kw
+	}
+	header := strings.TrimSpace(string(o.code[start:brace]))
+	if o.fam.arrows && strings.HasSuffix(header, "=>") {
+		return declFunction, ""
+	}
+	if container && strings.Contains(header, "(") && !controlWords[o.leadWord(start)] {
+		return declFunction, ""
+	}
+	return declOther, ""
+}
+
+// keyword returns the first declaration keyword of the family in
+// code[start:end], outside parentheses and brackets. A keyword must be
+// followed by a name, so that a method called "record" is not a record.
+func (o *braceOutliner) keyword(start, end int) string {
+	code := o.code
+	depth := 0
+	for i := start; i < end; i++ {
+		c := code[i]
+		switch {
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && isIdentByte(c) && (i == start || !isIdentByte(code[i-1]) && code[i-1] != '.'):
+			j := i
+			for j < end && isIdentByte(code[j]) {
+				j++
+			}
+			word := string(code[i:j])
+			if o.fam.containers[word] || o.fam.types[word] || o.fam.functions[word] {
+				if j < end && (isSpaceByte(code[j]) || code[j] == '<' && word == "impl" ||
+					(code[j] == '(' || code[j] == '*') && word == "function") {
+					return word
+				}
+			}
+			i = j - 1
+		}
+	}
+	return ""
+}
+
+// leadWord returns the first word of the statement at start, skipping
+// annotations, attributes and modifiers.
+func (o *braceOutliner) leadWord(start int) string {
+	code := o.code
+	i := start
+	for i < len(code) {
+		for i < len(code) && isSpaceByte(code[i]) {
+			i++
+		}
+		if i >= len(code) {
+			return ""
+		}
+		switch {
+		case code[i] == '@':
+			i++
+			for i < len(code) && (isIdentByte(code[i]) || code[i] == '.') {
+				i++
+			}
+			if i < len(code) && code[i] == '(' {
+				i = o.matchParen(i) + 1
+			}
+			continue
+		case code[i] == '#':
+			j := i + 1
+			if j < len(code) && code[j] == '!' {
+				j++
+			}
+			if j < len(code) && code[j] == '[' {
+				i = o.matchParen(j) + 1
+				continue
+			}
+			return ""
+		case !isIdentByte(code[i]):
+			return ""
+		}
+		j := i
+		for j < len(code) && isIdentByte(code[j]) {
+			j++
+		}
+		word := string(code[i:j])
+		if word == "pub" && j < len(code) && code[j] == '(' {
+			j = o.matchParen(j) + 1
+		}
+		if !modifiers[word] || word == "export" && o.fam.asi && o.exportList(j) {
+			return word
+		}
+		i = j
+	}
+	return ""
+}
+
+// exportList reports whether the export at i is followed by an export list
+// or "*", as in "export { a } from".
+func (o *braceOutliner) exportList(i int) bool {
+	rest := strings.TrimLeft(string(o.code[i:min(i+64, len(o.code))]), " \t\r\n")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "type"), " \t\r\n")
+	return strings.HasPrefix(rest, "{") || strings.HasPrefix(rest, "*")
+}
+
+// hasInlineBraces reports whether braces in the statement at start belong
+// to an import list, as in "import { a } from" or "use a::{b, c};".
+func (o *braceOutliner) hasInlineBraces(start int) bool {
+	switch o.leadWord(start) {
+	case "import", "use", "export":
+		return true
+	}
+	return false
+}
+
+// endsAtNewline reports whether the newline at i ends the statement that
+// started at start, following the spirit of automatic semicolon insertion.
+func (o *braceOutliner) endsAtNewline(start, i int) bool {
+	text := strings.TrimSpace(string(o.code[start:i]))
+	if text == "" {
+		return false
+	}
+	lastLine := text[strings.LastIndexByte(text, '\n')+1:]
+	if strings.HasPrefix(strings.TrimSpace(lastLine), "@") {
+		return false
+	}
+	if strings.HasSuffix(text, "=>") || strings.ContainsRune(",([=+-*/%&|^!?:<>.", rune(text[len(text)-1])) {
+		return false
+	}
+	next := strings.TrimLeft(string(o.code[i:min(i+32, len(o.code))]), " \t\r\n")
+	if next == "" {
+		return true
+	}
+	if strings.ContainsRune(".?:)],=+-*/%&|^<>{", rune(next[0])) {
+		return false
+	}
+	return !strings.HasPrefix(next, "extends") && !strings.HasPrefix(next, "implements")
+}
+
+// continuesAfterBlock reports whether a statement whose header is code goes
+// on after a block that is not a declaration body, as in "x = {...};".
+func continuesAfterBlock(header []byte) bool {
+	h := strings.TrimSpace(string(header))
+	return strings.ContainsAny(h, "=(") || strings.HasSuffix(h, ",")
+}
+
+// tail returns the end of the closing punctuation, such as ");", that
+// follows a block on the same line.
+func (o *braceOutliner) tail(i int) int {
+	end := i
+	for j := i; j < len(o.code); j++ {
+		c := o.code[j]
+		if c == ')' || c == ';' || c == ',' {
+			end = j + 1
+		} else if c != ' ' && c != '\t' {
+			break
+		}
+	}
+	return end
+}
+
+// matchBrace returns the position of the brace closing the one at i, or -1
+// after marking the outline failed when there is none.
+func (o *braceOutliner) matchBrace(i int) int {
+	depth := 0
+	for j := i; j < len(o.code); j++ {
+		switch o.code[j] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	o.failed = true
+	return -1
+}
+
+// matchParen returns the position of the bracket closing the one at i, or
+// the end of the code.
+func (o *braceOutliner) matchParen(i int) int {
+	open, close := o.code[i], byte(')')
+	if open == '[' {
+		close = ']'
+	}
+	depth := 0
+	for j := i; j < len(o.code); j++ {
+		switch o.code[j] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(o.code) - 1
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
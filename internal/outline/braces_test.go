@@ -0,0 +1,239 @@
+package outline
+
+import "testing"
+
+func TestBraceOutline(t *testing.T) {
+	tests := []struct {
+		lang string
+		src  string
+		want string
+	}{
+		{
+			lang: "typescript",
+			src: `import { a, b } from "./x";
+const fs = require("fs");
+const local = { a: 1 }
+
+/** Options for the thing. */
+export interface Options {
+  nested: { deep: number };
+}
+
+export type Id = string | number
+
+@Injectable()
+export class Service extends Base {
+  private count = 0
+
+  /** Fetch it. */
+  async fetch(url: string): Promise<void> {
+    const re = /}/g;
+  }
+
+  handler = (e: Event) => {
+    console.log(` + "`}${e}`" + `);
+  };
+}
+
+export const arrow = async (x) => {
+  return x;
+};
+
+describe("x", () => {
+  it("works", () => {});
+});
+`,
+			want: `import { a, b } from "./x";
+const fs = require("fs");
+
+/** Options for the thing. */
+export interface Options {
+  nested: { deep: number };
+}
+
+export type Id = string | number
+
+@Injectable()
+export class Service extends Base {
+  private count = 0
+
+  /** Fetch it. */
+  async fetch(url: string): Promise<void> { ... }
+
+  handler = (e: Event) => { ... };
+}
+
+export const arrow = async (x) => { ... };
+`,
+		},
+		{
+			lang: "java",
+			src: `package com.example;
+
+import java.util.List;
+
+/** A class. */
+public class A extends B {
+    private static final int X = 1;
+
+    static {
+        init();
+    }
+
+    @Override
+    public String toString() {
+        return "}";
+    }
+
+    public void record(String s) {
+        log(s);
+    }
+
+    interface Inner {
+        void run();
+    }
+}
+`,
+			want: `package com.example;
+
+import java.util.List;
+
+/** A class. */
+public class A extends B {
+    private static final int X = 1;
+
+    @Override
+    public String toString() { ... }
+
+    public void record(String s) { ... }
+
+    interface Inner {
+        void run();
+    }
+}
+`,
+		},
+		{
+			lang: "rust",
+			src: `//! Crate docs.
+
+use std::collections::{HashMap, HashSet};
+
+/// A point.
+#[derive(Debug)]
+pub struct Point {
+    pub x: i32,
+}
+
+static NAMES: &[&str] = &["a", "}"];
+
+pub trait Draw {
+    fn draw(&self) -> String;
+    fn name(&self) -> &str { "x" }
+}
+
+impl<T: fmt::Display> Draw for Wrapper<T> {
+    /// Draws.
+    fn draw(&self) -> String {
+        let s = r#"}"#;
+        format!("{}", self.0)
+    }
+}
+
+macro_rules! m { () => {} }
+`,
+			want: `//! Crate docs.
+
+use std::collections::{HashMap, HashSet};
+
+/// A point.
+#[derive(Debug)]
+pub struct Point {
+    pub x: i32,
+}
+
+pub trait Draw {
+    fn draw(&self) -> String;
+    fn name(&self) -> &str { ... }
+}
+
+impl<T: fmt::Display> Draw for Wrapper<T> {
+    /// Draws.
+    fn draw(&self) -> String { ... }
+}
+`,
+		},
+		{
+			lang: "java",
+			src: `public class Outer {
+    public interface Inner {
+        /** Runs. */
+        void run();
+
+        enum Kind {
+            A, B;
+            int code() {
+                return 1;
+            }
+        }
+    }
+    static class Box { int size() { return 0; } }
+}
+`,
+			want: `public class Outer {
+    public interface Inner {
+        /** Runs. */
+        void run();
+
+        enum Kind {
+            A, B;
+            int code() { ... }
+        }
+    }
+    static class Box {
+        int size() { ... }
+    }
+}
+`,
+		},
+		{
+			lang: "rust",
+			src: `mod shapes {
+  pub trait Area {
+    fn area(&self) -> f64;
+  }
+  impl Area for Square { fn area(&self) -> f64 { self.0 * self.0 } }
+}
+`,
+			want: `mod shapes {
+  pub trait Area {
+    fn area(&self) -> f64;
+  }
+  impl Area for Square {
+    fn area(&self) -> f64 { ... }
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			got, ok := Outline(tt.lang, tt.src)
+			if !ok {
+				t.Fatalf("Outline(%q) reported failure", tt.lang)
+			}
+			if got != tt.want {
+				t.Errorf("Outline(%q) =\n%s\nwant\n%s", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBraceOutline_Unbalanced(t *testing.T) {
+	for _, src := range []string{"function f() {\n  return 1\n", "}\nclass A {}\n"} {
+		if got, ok := Outline("javascript", src); ok || got != src {
+			t.Errorf("Outline(%q) = %q, %v; want input unchanged and false", src, got, ok)
+		}
+	}
+}
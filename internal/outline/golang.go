@@ -0,0 +1,89 @@
+package outline
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// goOutline keeps the package clause, imports, type declarations and the
+// signatures of functions and methods, each with its doc comment. Function
+// bodies are replaced by "{ ... }".
+func goOutline(src string) (string, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return src, false
+	}
+
+	var sb strings.Builder
+	if file.Doc != nil {
+		sb.WriteString(commentText(file.Doc))
+	}
+	sb.WriteString("package " + file.Name.Name + "\n")
+
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	for _, decl := range file.Decls {
+		var node any
+		var comments []*ast.CommentGroup
+		body := false
+
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.IMPORT && d.Tok != token.TYPE {
+				continue
+			}
+			node = d
+			comments = commentsWithin(file, declStart(d.Doc, d.Pos()), d.End())
+		case *ast.FuncDecl:
+			sig := *d
+			sig.Body = nil
+			body = d.Body != nil
+			node = &sig
+			if d.Doc != nil {
+				comments = []*ast.CommentGroup{d.Doc}
+			}
+		default:
+			continue
+		}
+
+		sb.WriteString("\n")
+		if err := cfg.Fprint(&sb, fset, &printer.CommentedNode{Node: node, Comments: comments}); err != nil {
+			return src, false
+		}
+		if body {
+			sb.WriteString(" { ... }")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), true
+}
+
+// commentsWithin returns the comment groups of file that lie in [start, end).
+func commentsWithin(file *ast.File, start, end token.Pos) []*ast.CommentGroup {
+	var groups []*ast.CommentGroup
+	for _, cg := range file.Comments {
+		if cg.Pos() >= start && cg.End() <= end {
+			groups = append(groups, cg)
+		}
+	}
+	return groups
+}
+
+func declStart(doc *ast.CommentGroup, pos token.Pos) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return pos
+}
+
+// commentText renders a comment group verbatim, one comment per line.
+func commentText(cg *ast.CommentGroup) string {
+	var sb strings.Builder
+	for _, c := range cg.List {
+		sb.WriteString(c.Text + "\n")
+	}
+	return sb.String()
+}
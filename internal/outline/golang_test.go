@@ -0,0 +1,77 @@
+package outline
+
+import "testing"
+
+func TestGoOutline(t *testing.T) {
+	src := `// Package shop sells things.
+package shop
+
+import (
+	"fmt"
+	"strings"
+)
+
+const limit = 10
+
+// Item is for sale.
+type Item struct {
+	// Name is shown to customers.
+	Name  string
+	Price int // in cents
+}
+
+// String formats the item.
+func (i Item) String() string {
+	// not part of the outline
+	return fmt.Sprintf("%s: %d", strings.ToUpper(i.Name), i.Price)
+}
+
+func helper(a, b int) (int, error) {
+	return a + b, nil
+}
+`
+	want := `// Package shop sells things.
+package shop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Item is for sale.
+type Item struct {
+	// Name is shown to customers.
+	Name  string
+	Price int // in cents
+}
+
+// String formats the item.
+func (i Item) String() string { ... }
+
+func helper(a, b int) (int, error) { ... }
+`
+	got, ok := Outline("go", src)
+	if !ok {
+		t.Fatal("Outline reported failure for valid Go")
+	}
+	if got != want {
+		t.Errorf("Outline =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGoOutline_ParseError(t *testing.T) {
+	src := "package broken\n\nfunc f( {\n"
+	got, ok := Outline("go", src)
+	if ok || got != src {
+		t.Errorf("Outline = %q, %v; want input unchanged and false", got, ok)
+	}
+}
+
+func TestOutline_Unsupported(t *testing.T) {
+	if Supports("cobol") {
+		t.Error("Supports(cobol) = true")
+	}
+	if _, ok := Outline("cobol", "x"); ok {
+		t.Error("Outline(cobol) reported success")
+	}
+}
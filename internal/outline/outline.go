@@ -0,0 +1,120 @@
+// Package outline reduces source files to their declarations, dropping
+// function bodies, so that a model can see the shape of a codebase for a
+// fraction of the tokens.
+package outline
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+)
+
+// outliners maps a language name, as returned by langutil.Detect, to the
+// function that outlines it.
+var outliners = map[string]func(src string) (string, bool){
+	"go":         goOutline,
+	"python":     pythonOutline,
+	"javascript": braceOutline("javascript", jsFamily),
+	"jsx":        braceOutline("jsx", jsFamily),
+	"typescript": braceOutline("typescript", jsFamily),
+	"tsx":        braceOutline("tsx", jsFamily),
+	"java":       braceOutline("java", javaFamily),
+	"rust":       braceOutline("rust", rustFamily),
+}
+
+// Languages returns the languages that can be outlined, sorted by name.
+func Languages() []string {
+	names := make([]string, 0, len(outliners))
+	for name := range outliners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Supports reports whether lang can be outlined.
+func Supports(lang string) bool {
+	_, ok := outliners[lang]
+	return ok
+}
+
+// Outline returns the outline of src, written in lang. ok is false when lang
+// is not supported or src cannot be parsed; callers should then keep the
+// full content.
+func Outline(lang, src string) (out string, ok bool) {
+	fn, found := outliners[lang]
+	if !found {
+		return src, false
+	}
+	return fn(src)
+}
+
+// maskCode returns src with comments blanked to spaces and the inside of
+// string literals replaced by underscores, so that heuristic parsers only
+// see code. Newlines in comments are kept; newlines in strings are not, so
+// a multi-line string never ends a line.
+func maskCode(src string, spans []langutil.Span) []byte {
+	code := []byte(src)
+	for _, s := range spans {
+		for i := s.Start; i < s.End; i++ {
+			switch {
+			case s.Kind == langutil.SpanComment && code[i] != '\n':
+				code[i] = ' '
+			case s.Kind == langutil.SpanString && i > s.Start && i < s.End-1:
+				code[i] = '_'
+			}
+		}
+	}
+	return code
+}
+
+// outlineWriter collects the kept items of an outline, one or more lines
+// each, and keeps a blank line between two items wherever the source had
+// one in the text between them.
+type outlineWriter struct {
+	src  string
+	sb   strings.Builder
+	last int
+}
+
+// item writes text, the outline of src[start:end].
+func (w *outlineWriter) item(start, end int, text string) {
+	if w.sb.Len() > 0 && hasBlankLine(w.src[w.last:start]) {
+		w.sb.WriteByte('\n')
+	}
+	w.sb.WriteString(text)
+	w.sb.WriteByte('\n')
+	w.last = end
+}
+
+func hasBlankLine(s string) bool {
+	for {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return false
+		}
+		s = s[i+1:]
+		j := strings.IndexByte(s, '\n')
+		if j < 0 {
+			return false
+		}
+		if strings.TrimSpace(s[:j]) == "" {
+			return true
+		}
+	}
+}
+
+// lineIndent returns the leading whitespace of the line containing src[i].
+func lineIndent(src string, i int) string {
+	start := strings.LastIndexByte(src[:i], '\n') + 1
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[start:end]
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
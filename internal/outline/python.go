@@ -0,0 +1,218 @@
+package outline
+
+import (
+	"strings"
+
+	"github.com/ga1az/pathdigest/internal/langutil"
+)
+
+// pyLine is a logical line of Python source: one or more physical lines
+// joined by open brackets or backslashes.
+type pyLine struct {
+	start, end int
+	indent     int
+}
+
+type pyBlock struct {
+	indent     int
+	class      bool
+	bodyIndent string
+	members    int
+}
+
+// pythonOutline keeps imports, the module docstring and class and function
+// headers with their decorators and docstrings. Function bodies become
+// "...", and class bodies keep their attributes and methods. The structure
+// comes from indentation; unbalanced brackets or a def without a colon make
+// the outline fail.
+func pythonOutline(src string) (string, bool) {
+	spans, _ := langutil.Lex("python", src)
+	code := maskCode(src, spans)
+	lines, ok := pyLogicalLines(src, code)
+	if !ok {
+		return src, false
+	}
+
+	w := outlineWriter{src: src}
+	var stack []*pyBlock
+	closeBlock := func() {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top.class && top.members == 0 {
+			w.sb.WriteString(top.bodyIndent + "...\n")
+		}
+	}
+	decorators := -1
+
+	for n, l := range lines {
+		for len(stack) > 0 && l.indent <= stack[len(stack)-1].indent {
+			closeBlock()
+		}
+		if len(stack) > 0 && !stack[len(stack)-1].class {
+			continue
+		}
+		text := string(code[l.start:l.end])
+		word := firstWord(text)
+
+		if strings.HasPrefix(text, "@") {
+			if decorators < 0 {
+				decorators = l.start
+			}
+			continue
+		}
+		start := l.start
+		if decorators >= 0 {
+			start = decorators
+			decorators = -1
+		}
+
+		switch {
+		case word == "def" || word == "class" || word == "async" && firstWord(strings.TrimSpace(text[5:])) == "def":
+			colon := headerColon(text)
+			if colon < 0 {
+				return src, false
+			}
+			if len(stack) > 0 {
+				stack[len(stack)-1].members++
+			}
+			indent := lineIndent(src, l.start)
+			header := indent + src[start:l.start+colon+1]
+			if strings.TrimSpace(text[colon+1:]) != "" {
+				w.item(start, l.end, header+" ...")
+				continue
+			}
+
+			block := &pyBlock{indent: l.indent, class: word == "class", bodyIndent: indent + "    "}
+			end := l.end
+			var doc string
+			if n+1 < len(lines) && lines[n+1].indent > l.indent {
+				next := lines[n+1]
+				block.bodyIndent = lineIndent(src, next.start)
+				if isDocstring(spans, next) {
+					doc = "\n" + block.bodyIndent + src[next.start:next.end]
+					block.members++
+					end = next.end
+				}
+			}
+			if !block.class && doc == "" {
+				doc = "\n" + block.bodyIndent + "..."
+			}
+			w.item(start, end, header+doc)
+			stack = append(stack, block)
+
+		case len(stack) == 0 && (word == "import" || word == "from" || n == 0 && isDocstring(spans, l)):
+			w.item(l.start, l.end, src[l.start:l.end])
+
+		case len(stack) > 0 && isAttribute(text):
+			stack[len(stack)-1].members++
+			w.item(l.start, l.end, lineIndent(src, l.start)+src[l.start:l.end])
+		}
+	}
+	for len(stack) > 0 {
+		closeBlock()
+	}
+	return w.sb.String(), true
+}
+
+// pyLogicalLines splits the masked code into non-blank logical lines. ok is
+// false when brackets are unbalanced.
+func pyLogicalLines(src string, code []byte) ([]pyLine, bool) {
+	var lines []pyLine
+	depth := 0
+	start := 0
+	for i := 0; i <= len(code); i++ {
+		if i < len(code) {
+			switch code[i] {
+			case '(', '[', '{':
+				depth++
+				continue
+			case ')', ']', '}':
+				depth--
+				if depth < 0 {
+					return nil, false
+				}
+				continue
+			case '\n':
+				if depth > 0 || i > 0 && code[i-1] == '\\' {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if depth > 0 {
+			return nil, false
+		}
+		text := strings.TrimRight(string(code[start:i]), " \t\r")
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed != "" {
+			ws := len(text) - len(trimmed)
+			lines = append(lines, pyLine{start: start + ws, end: start + len(text), indent: indentWidth(src[start : start+ws])})
+		}
+		start = i + 1
+	}
+	return lines, true
+}
+
+func indentWidth(ws string) int {
+	width := 0
+	for _, c := range ws {
+		if c == '\t' {
+			width += 8 - width%8
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// headerColon returns the offset of the colon ending a def or class header
+// in text, or -1.
+func headerColon(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ':':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isDocstring reports whether the logical line l is a lone string literal.
+func isDocstring(spans []langutil.Span, l pyLine) bool {
+	for _, s := range spans {
+		if s.Kind == langutil.SpanString && s.Start >= l.start && s.Start <= l.start+2 {
+			return s.End == l.end
+		}
+		if s.Start > l.start+2 {
+			break
+		}
+	}
+	return false
+}
+
+// isAttribute reports whether text, a statement in a class body, assigns
+// or annotates a class attribute.
+func isAttribute(text string) bool {
+	n := 0
+	for n < len(text) && isIdentByte(text[n]) {
+		n++
+	}
+	rest := strings.TrimLeft(text[n:], " \t")
+	return n > 0 && (strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "=="))
+}
+
+func firstWord(text string) string {
+	n := 0
+	for n < len(text) && isIdentByte(text[n]) {
+		n++
+	}
+	return text[:n]
+}
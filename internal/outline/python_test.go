@@ -0,0 +1,81 @@
+package outline
+
+import "testing"
+
+func TestPythonOutline(t *testing.T) {
+	src := `"""Module doc."""
+import os
+from typing import (
+    Dict,
+)
+
+CONST = 1
+
+
+@dataclass
+class Point(Base):
+    """A point."""
+
+    x: int = 0
+
+    def __init__(self, x: int) -> None:
+        """Make one."""
+        self.x = x  # def inside a comment
+        s = """
+def not_real():
+"""
+
+    async def fetch(self,
+                    url: str) -> Dict[str, int]:
+        pass
+
+
+class Empty:
+    pass
+
+
+def helper(a, b=lambda: 1): return a
+
+
+if __name__ == "__main__":
+    main()
+`
+	want := `"""Module doc."""
+import os
+from typing import (
+    Dict,
+)
+
+@dataclass
+class Point(Base):
+    """A point."""
+
+    x: int = 0
+
+    def __init__(self, x: int) -> None:
+        """Make one."""
+
+    async def fetch(self,
+                    url: str) -> Dict[str, int]:
+        ...
+
+class Empty:
+    ...
+
+def helper(a, b=lambda: 1): ...
+`
+	got, ok := Outline("python", src)
+	if !ok {
+		t.Fatal("Outline reported failure for valid Python")
+	}
+	if got != want {
+		t.Errorf("Outline =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPythonOutline_Unbalanced(t *testing.T) {
+	src := "def f(:\n    pass\n"
+	if got, ok := Outline("python", src); ok || got != src {
+		t.Errorf("Outline = %q, %v; want input unchanged and false", got, ok)
+	}
+}
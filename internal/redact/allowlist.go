@@ -0,0 +1,84 @@
+package redact
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Allowlist suppresses known false positives. A nil *Allowlist allows
+// nothing.
+type Allowlist struct {
+	// Fingerprints are SHA-256 hashes of secrets that may stay.
+	Fingerprints map[string]bool
+	// Paths are glob patterns of files that are not scanned.
+	Paths []string
+	// Patterns match secrets that may stay.
+	Patterns []*regexp.Regexp
+}
+
+// LoadAllowlist reads an allowlist file. Each line holds one entry:
+//
+//	sha256:<hex>   a secret's fingerprint, as printed in findings reports
+//	path:<glob>    files that are not scanned
+//	regex:<expr>   secrets matching the regular expression
+//
+// Blank lines and lines starting with "#" are ignored.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets allowlist: %w", err)
+	}
+	defer f.Close()
+
+	allow := &Allowlist{Fingerprints: make(map[string]bool)}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("%s:%d: empty %s entry", path, n, kind)
+		}
+		switch kind {
+		case "sha256":
+			allow.Fingerprints[strings.ToLower(value)] = true
+		case "path":
+			allow.Paths = append(allow.Paths, value)
+		case "regex":
+			re, errRe := regexp.Compile(value)
+			if errRe != nil {
+				return nil, fmt.Errorf("%s:%d: invalid regex: %w", path, n, errRe)
+			}
+			allow.Patterns = append(allow.Patterns, re)
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown entry %q (use sha256:, path: or regex:)", path, n, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read secrets allowlist: %w", err)
+	}
+	return allow, nil
+}
+
+// AllowsSecret reports whether secret is allowlisted by fingerprint or
+// pattern.
+func (a *Allowlist) AllowsSecret(secret string) bool {
+	if a == nil {
+		return false
+	}
+	if a.Fingerprints[Fingerprint(secret)] {
+		return true
+	}
+	for _, re := range a.Patterns {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
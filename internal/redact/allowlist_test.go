@@ -0,0 +1,45 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAllowlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allow.txt")
+	data := "# known test fixtures\n\nsha256:" + strings.ToUpper(Fingerprint(awsKeyID)) + "\npath: testdata/\nregex:^ghp_(aB3)+$\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	allow, err := LoadAllowlist(path)
+	if err != nil {
+		t.Fatalf("LoadAllowlist returned error: %v", err)
+	}
+	if len(allow.Paths) != 1 || allow.Paths[0] != "testdata/" {
+		t.Errorf("Paths = %v", allow.Paths)
+	}
+	if !allow.AllowsSecret(awsKeyID) || !allow.AllowsSecret(githubPAT) {
+		t.Error("allowlisted secrets were not allowed")
+	}
+	if allow.AllowsSecret("AKIA" + "ZZZZZZZZZZZZZZZZ") {
+		t.Error("a secret outside the allowlist was allowed")
+	}
+	if got := Scan(awsKeyID+" "+githubPAT, allow); len(got) != 0 {
+		t.Errorf("Scan with allowlist found %+v", got)
+	}
+}
+
+func TestLoadAllowlist_Errors(t *testing.T) {
+	for _, data := range []string{"fingerprint:abc\n", "regex:(\n", "path:\n"} {
+		path := filepath.Join(t.TempDir(), "allow.txt")
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadAllowlist(path); err == nil {
+			t.Errorf("LoadAllowlist(%q) succeeded, want an error", data)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PIIRule detects one kind of personal data. Matches are replaced by
+// placeholders named after Label, such as <EMAIL_1>.
+type PIIRule struct {
+	Label   string
+	Pattern *regexp.Regexp
+	// Group is the submatch that holds the value; 0 is the whole match.
+	Group int
+	// Valid, when set, rejects matches that only look like the data, such
+	// as card numbers failing the Luhn check.
+	Valid func(value string) bool
+}
+
+// PIIRules are the built-in detectors.
+var PIIRules = []PIIRule{
+	{Label: "EMAIL", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{Label: "CREDIT_CARD", Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), Valid: cardValid},
+	{Label: "SSN", Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), Valid: ssnValid},
+	{Label: "PHONE", Pattern: regexp.MustCompile(`(?:^|[^\w+(])(\+[1-9]\d{0,2}[ .\-]?(?:\(?\d{1,4}\)?[ .\-]?){2,4}\d{2,4}|\(?\d{3}\)?[ .\-]\d{3}[ .\-]\d{4})\b`), Group: 1, Valid: phoneValid},
+	{Label: "IPV4", Pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), Valid: ipv4Valid},
+	{Label: "IPV6", Pattern: regexp.MustCompile(`(?i)(?:^|[^\w:])((?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4})`), Group: 1, Valid: ipv6Valid},
+}
+
+// PIIRedactor replaces personal data with placeholders. The same value gets
+// the same placeholder in every file it redacts, so references stay
+// consistent across a digest.
+type PIIRedactor struct {
+	rules        []PIIRule
+	placeholders map[string]string
+	next         map[string]int
+}
+
+// NewPIIRedactor returns a redactor for rules, which are applied in order.
+func NewPIIRedactor(rules []PIIRule) *PIIRedactor {
+	return &PIIRedactor{rules: rules, placeholders: make(map[string]string), next: make(map[string]int)}
+}
+
+// Redact replaces the personal data in content and returns the number of
+// redactions per label.
+func (p *PIIRedactor) Redact(content string) (string, map[string]int) {
+	type match struct {
+		start, end int
+		rule       *PIIRule
+	}
+	var matches []match
+	for i := range p.rules {
+		rule := &p.rules[i]
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[2*rule.Group], m[2*rule.Group+1]
+			if start < 0 || rule.Valid != nil && !rule.Valid(content[start:end]) {
+				continue
+			}
+			overlap := false
+			for _, prev := range matches {
+				if start < prev.end && prev.start < end {
+					overlap = true
+					break
+				}
+			}
+			if !overlap {
+				matches = append(matches, match{start, end, rule})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return content, nil
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	counts := make(map[string]int)
+	var sb strings.Builder
+	pos := 0
+	for _, m := range matches {
+		sb.WriteString(content[pos:m.start])
+		sb.WriteString(p.placeholder(m.rule.Label, content[m.start:m.end]))
+		counts[m.rule.Label]++
+		pos = m.end
+	}
+	sb.WriteString(content[pos:])
+	return sb.String(), counts
+}
+
+func (p *PIIRedactor) placeholder(label, value string) string {
+	key := label + "\x00" + value
+	if ph, ok := p.placeholders[key]; ok {
+		return ph
+	}
+	p.next[label]++
+	ph := fmt.Sprintf("<%s_%d>", label, p.next[label])
+	p.placeholders[key] = ph
+	return ph
+}
+
+// PIIConfig is the file format of custom PII rules:
+//
+//	{
+//	  "disable": ["IPV4"],
+//	  "rules": [{"label": "EMPLOYEE_ID", "pattern": "EMP-[0-9]{6}"}]
+//	}
+type PIIConfig struct {
+	// Disable lists built-in rules to turn off, by label.
+	Disable []string `json:"disable,omitempty"`
+	Rules   []struct {
+		Label   string `json:"label"`
+		Pattern string `json:"pattern"`
+	} `json:"rules"`
+}
+
+var piiLabelPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// LoadPIIRules returns the built-in rules, minus any the config file at path
+// disables, followed by the config's own rules.
+func LoadPIIRules(path string) ([]PIIRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PII config: %w", err)
+	}
+	var cfg PIIConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse PII config %s: %w", path, err)
+	}
+
+	disabled := make(map[string]bool)
+	for _, label := range cfg.Disable {
+		disabled[label] = true
+	}
+	var rules []PIIRule
+	for _, rule := range PIIRules {
+		if !disabled[rule.Label] {
+			rules = append(rules, rule)
+		}
+	}
+	for i, r := range cfg.Rules {
+		if !piiLabelPattern.MatchString(r.Label) {
+			return nil, fmt.Errorf("PII config %s: rule %d: label %q must be upper case, like EMPLOYEE_ID", path, i+1, r.Label)
+		}
+		re, errRe := regexp.Compile(r.Pattern)
+		if errRe != nil {
+			return nil, fmt.Errorf("PII config %s: rule %s: %w", path, r.Label, errRe)
+		}
+		rules = append(rules, PIIRule{Label: r.Label, Pattern: re})
+	}
+	return rules, nil
+}
+
+// cardValid accepts numbers with a card network prefix that pass the Luhn
+// check.
+func cardValid(s string) bool {
+	return strings.IndexByte("23456", s[0]) >= 0 && luhnValid(s)
+}
+
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+func ssnValid(s string) bool {
+	area := s[:3]
+	return area != "000" && area != "666" && area[0] != '9' && s[4:6] != "00" && s[7:] != "0000"
+}
+
+func phoneValid(s string) bool {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits++
+		}
+	}
+	return digits >= 10 && digits <= 15
+}
+
+// ipv4Valid accepts addresses that identify a host; loopback, unspecified
+// and broadcast addresses stay.
+func ipv4Valid(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() == nil {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsUnspecified() && !ip.Equal(net.IPv4bcast)
+}
+
+func ipv6Valid(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil && !ip.IsLoopback() && !ip.IsUnspecified() &&
+		strings.ContainsAny(s, "0123456789")
+}
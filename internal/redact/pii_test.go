@@ -0,0 +1,75 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPIIRedactor(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"email", "contact: jane.doe@example.com", "contact: <EMAIL_1>"},
+		{"phone", "call (555) 123-4567 or +44 20 7946 0958", "call <PHONE_1> or <PHONE_2>"},
+		{"ipv4", "host 203.0.113.7, local 127.0.0.1", "host <IPV4_1>, local 127.0.0.1"},
+		{"ipv6", "addr 2001:db8::8a2e:370:7334 and std::fmt", "addr <IPV6_1> and std::fmt"},
+		{"credit card", "card 4111 1111 1111 1111, id 1234567890123", "card <CREDIT_CARD_1>, id 1234567890123"},
+		{"ssn", "ssn 123-45-6789, not 000-12-3456", "ssn <SSN_1>, not 000-12-3456"},
+		{"version and time", "v1.2.3 at 12:30:45", "v1.2.3 at 12:30:45"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := NewPIIRedactor(PIIRules).Redact(tt.in)
+			if got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPIIRedactor_StablePlaceholders(t *testing.T) {
+	p := NewPIIRedactor(PIIRules)
+	first, counts := p.Redact("from a@example.com to b@example.com, cc a@example.com")
+	if first != "from <EMAIL_1> to <EMAIL_2>, cc <EMAIL_1>" {
+		t.Errorf("first file = %q", first)
+	}
+	if !reflect.DeepEqual(counts, map[string]int{"EMAIL": 3}) {
+		t.Errorf("counts = %v", counts)
+	}
+
+	second, _ := p.Redact("reply to b@example.com")
+	if second != "reply to <EMAIL_2>" {
+		t.Errorf("second file = %q, want the placeholder from the first file", second)
+	}
+}
+
+func TestLoadPIIRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pii.json")
+	data := `{"disable": ["IPV4"], "rules": [{"label": "EMPLOYEE_ID", "pattern": "EMP-[0-9]{6}"}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadPIIRules(path)
+	if err != nil {
+		t.Fatalf("LoadPIIRules returned error: %v", err)
+	}
+	got, counts := NewPIIRedactor(rules).Redact("EMP-123456 at 203.0.113.7")
+	if got != "<EMPLOYEE_ID_1> at 203.0.113.7" || counts["EMPLOYEE_ID"] != 1 {
+		t.Errorf("Redact = %q, %v", got, counts)
+	}
+
+	for _, bad := range []string{`{"rules": [{"label": "lower", "pattern": "x"}]}`, `{"rules": [{"label": "X", "pattern": "("}]}`, `not json`} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPIIRules(path); err == nil {
+			t.Errorf("LoadPIIRules(%s) succeeded, want an error", bad)
+		}
+	}
+}
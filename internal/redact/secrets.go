@@ -0,0 +1,149 @@
+// Package redact finds secrets in file content and replaces them before the
+// content reaches a digest.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule detects one kind of secret.
+type Rule struct {
+	ID      string
+	Pattern *regexp.Regexp
+	// Group is the submatch that holds the secret; 0 is the whole match.
+	Group int
+	// MinEntropy, when positive, is the Shannon entropy in bits per byte
+	// that the secret must reach, to tell keys from ordinary words.
+	MinEntropy float64
+	// Mixed requires the secret to contain both letters and digits, which
+	// rules out identifiers such as config.APIToken.
+	Mixed bool
+}
+
+// Finding is a secret found in a piece of content.
+type Finding struct {
+	RuleID string
+	// Line is the 1-based line of the secret's first byte.
+	Line int
+	// Start and End are the byte offsets of the secret.
+	Start, End int
+	// Fingerprint is the hex SHA-256 of the secret, which identifies it in
+	// reports and allowlists without revealing it.
+	Fingerprint string
+}
+
+const secretValue = `([A-Za-z0-9+/=_\-.~!@#%^&*]{16,})`
+
+// Rules are the built-in secret detectors, most specific first.
+var Rules = []Rule{
+	{ID: "private-key", Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{ID: "aws-access-key-id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)},
+	{ID: "aws-secret-access-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*(?::=|=>|[:=])\s*["']?([A-Za-z0-9/+=]{40})(?:[^A-Za-z0-9/+=]|$)`), Group: 1},
+	{ID: "gcp-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{ID: "azure-storage-key", Pattern: regexp.MustCompile(`(?i)AccountKey=([A-Za-z0-9+/]{86}==)`), Group: 1},
+	{ID: "github-token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{ID: "gitlab-token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20}\b`)},
+	{ID: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{ID: "slack-webhook", Pattern: regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`)},
+	{ID: "stripe-key", Pattern: regexp.MustCompile(`\b(?:sk|rk)_live_[A-Za-z0-9]{24,}\b`)},
+	{ID: "anthropic-api-key", Pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{32,}`)},
+	{ID: "openai-api-key", Pattern: regexp.MustCompile(`\bsk-(?:proj-|svcacct-)?[A-Za-z0-9_\-]{40,}`)},
+	{ID: "npm-token", Pattern: regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`)},
+	{ID: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{ID: "url-credentials", Pattern: regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9+.-]*://[^\s:/@"']+:([^\s:/@"']{4,})@`), Group: 1},
+	{
+		ID:         "generic-secret",
+		Pattern:    regexp.MustCompile(`(?i)[A-Za-z0-9_.-]*(?:secret|passw(?:or)?d|pwd|token|api_?key|access_?key|auth_?key|credential|private_?key)[A-Za-z0-9_.-]*["']?\s*(?::=|=>|[:=])\s*["'` + "`" + `]?` + secretValue),
+		Group:      1,
+		MinEntropy: 3.5,
+		Mixed:      true,
+	},
+}
+
+// Scan returns the secrets in content that allow does not permit, in order
+// of position. Overlapping matches are reported once, by the first rule.
+func Scan(content string, allow *Allowlist) []Finding {
+	var findings []Finding
+	for _, rule := range Rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[2*rule.Group], m[2*rule.Group+1]
+			if start < 0 {
+				continue
+			}
+			secret := content[start:end]
+			if rule.MinEntropy > 0 && entropy(secret) < rule.MinEntropy || rule.Mixed && !hasLettersAndDigits(secret) {
+				continue
+			}
+			if overlaps(findings, start, end) || allow.AllowsSecret(secret) {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      rule.ID,
+				Line:        strings.Count(content[:start], "\n") + 1,
+				Start:       start,
+				End:         end,
+				Fingerprint: Fingerprint(secret),
+			})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+// Redact replaces every finding in content with "[REDACTED:<rule>]",
+// followed by the newlines of the secret so that line numbers are kept.
+// findings must come from Scan on the same content.
+func Redact(content string, findings []Finding) string {
+	var sb strings.Builder
+	pos := 0
+	for _, f := range findings {
+		sb.WriteString(content[pos:f.Start])
+		sb.WriteString("[REDACTED:" + f.RuleID + "]")
+		sb.WriteString(strings.Repeat("\n", strings.Count(content[f.Start:f.End], "\n")))
+		pos = f.End
+	}
+	sb.WriteString(content[pos:])
+	return sb.String()
+}
+
+// Fingerprint returns the hex SHA-256 of secret.
+func Fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func overlaps(findings []Finding, start, end int) bool {
+	for _, f := range findings {
+		if start < f.End && f.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLettersAndDigits(s string) bool {
+	letter := strings.IndexFunc(s, func(r rune) bool { return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }) >= 0
+	digit := strings.IndexFunc(s, func(r rune) bool { return r >= '0' && r <= '9' }) >= 0
+	return letter && digit
+}
+
+// entropy returns the Shannon entropy of s in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(s))
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
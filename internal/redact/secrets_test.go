@@ -0,0 +1,66 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test secrets are assembled at run time so that the source itself does not
+// trip secret scanners.
+var (
+	awsKeyID  = "AKIA" + "IOSFODNN7EXAMPLE"
+	githubPAT = "ghp_" + strings.Repeat("aB3", 12)
+	privKey   = "-----BEGIN RSA " + "PRIVATE KEY-----\nMIIEowIBAAKCAQEA\n-----END RSA " + "PRIVATE KEY-----"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // rule IDs in order
+	}{
+		{"aws key id", `key := "` + awsKeyID + `"`, []string{"aws-access-key-id"}},
+		{"aws secret", "aws_secret_access_key = " + "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", []string{"aws-secret-access-key"}},
+		{"private key", "x\n" + privKey + "\ny", []string{"private-key"}},
+		{"github token", "token: " + githubPAT, []string{"github-token"}},
+		{"url credentials", "DATABASE_URL=postgres://admin:" + "s3cr3tP4ss@db/app", []string{"url-credentials"}},
+		{"generic high entropy", `apiToken = "q8Zf3kLp0xV7` + `mN2bR9tY4wHs"`, []string{"generic-secret"}},
+		{"generic low entropy", `password = "aaaaaaaaaaaaaaaaaaaa1"`, nil},
+		{"identifier value", "TokensBefore: total.TokensBefore,", nil},
+		{"short password", `password = "hunter2"`, nil},
+		{"two findings", awsKeyID + " " + githubPAT, []string{"aws-access-key-id", "github-token"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range Scan(tt.content, nil) {
+				got = append(got, f.RuleID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Scan found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	content := "a\nid = " + awsKeyID + "\npat = " + githubPAT + "\n"
+	findings := Scan(content, nil)
+	if len(findings) != 2 || findings[0].Line != 2 || findings[1].Line != 3 {
+		t.Fatalf("unexpected findings %+v", findings)
+	}
+	if findings[0].Fingerprint != Fingerprint(awsKeyID) {
+		t.Errorf("fingerprint = %s, want the SHA-256 of the secret", findings[0].Fingerprint)
+	}
+
+	want := "a\nid = [REDACTED:aws-access-key-id]\npat = [REDACTED:github-token]\n"
+	if got := Redact(content, findings); got != want {
+		t.Errorf("Redact = %q, want %q", got, want)
+	}
+
+	content = "x\n" + privKey + "\ny\n"
+	if got, want := Redact(content, Scan(content, nil)), "x\n[REDACTED:private-key]\n\n\ny\n"; got != want {
+		t.Errorf("Redact of a multi-line secret = %q, want %q (line count kept)", got, want)
+	}
+}